@@ -0,0 +1,169 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// AIR is the public description of a computation that can be proven with the stark package:
+// the shape of its execution trace and the constraints the trace satisfies
+type AIR interface {
+	// TraceLength returns the number of rows of the trace, it must be a power of two
+	TraceLength() int
+
+	// TraceWidth returns the number of columns of the trace
+	TraceWidth() int
+
+	// TransitionDegree returns the maximal degree of the transition constraints in the trace values
+	TransitionDegree() int
+
+	// NumTransitionConstraints returns the number of values returned by EvaluateTransition
+	NumTransitionConstraints() int
+
+	// EvaluateTransition evaluates the transition constraints on two consecutive rows,
+	// the constraints must vanish on every step except the last one
+	EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField
+
+	// Assertions returns the boundary constraints
+	Assertions() []Assertion
+}
+
+// Check checks that the trace has the shape of the AIR and satisfies all of its constraints
+func Check(a AIR, trace ExecutionTrace) error {
+	if err := checkShape(a, trace); err != nil {
+		return err
+	}
+
+	if err := CheckAssertions(trace, a.Assertions()); err != nil {
+		return err
+	}
+
+	periodic := Periodic(a)
+	if err := CheckPeriodic(periodic, len(trace)); err != nil {
+		return err
+	}
+
+	return CheckTransitions(trace, func(step int, current, next []*math.PrimeField) []*math.PrimeField {
+		return a.EvaluateTransition(WithPeriodic(current, periodic, step), WithPeriodic(next, periodic, step+1))
+	})
+}
+
+func checkShape(a AIR, trace ExecutionTrace) error {
+	if len(trace) != a.TraceLength() {
+		return fmt.Errorf("trace has %d rows, expected %d", len(trace), a.TraceLength())
+	}
+
+	for i, row := range trace {
+		if row.Len() != a.TraceWidth() {
+			return fmt.Errorf("row %d has width %d, expected %d", i, row.Len(), a.TraceWidth())
+		}
+	}
+
+	return nil
+}
+
+// PublicInputsAIR is an AIR whose statement includes public values that are not the values of its assertions,
+// e.g. values read by the transition constraints or by the assertions of auxiliary segments.
+// Provers and verifiers bind the transcript to them before drawing any challenge.
+type PublicInputsAIR interface {
+	AIR
+
+	// PublicInputs returns the public values of the statement that Assertions does not return
+	PublicInputs() []*math.PrimeField
+}
+
+// PublicInputs returns the public inputs of the AIR, nil if it has none
+func PublicInputs(a AIR) []*math.PrimeField {
+	if p, ok := a.(PublicInputsAIR); ok {
+		return p.PublicInputs()
+	}
+
+	return nil
+}
+
+// AuxiliarySegment is a trace segment built after the main trace and every previous segment are committed,
+// from their values and random challenges drawn after those commitments.
+// Permutation and lookup arguments need such segments to bind their running products and sums to the challenges.
+//
+// Segment constraints are evaluated on rows of the main trace, of every previous segment and of the segment itself,
+// in this order, and share TransitionDegree with the main constraints.
+type AuxiliarySegment interface {
+	// Width returns the number of columns of the segment
+	Width() int
+
+	// NumChallenges returns the number of random challenges the segment depends on
+	NumChallenges() int
+
+	// Build builds the segment from the main trace followed by the previous segments and the challenges
+	Build(segments []ExecutionTrace, challenges []*math.PrimeField) ExecutionTrace
+
+	// NumConstraints returns the number of values returned by Evaluate
+	NumConstraints() int
+
+	// Evaluate evaluates the transition constraints of the segment on two consecutive rows of every segment up to it,
+	// the constraints must vanish on every step except the last one
+	Evaluate(current, next [][]*math.PrimeField, challenges []*math.PrimeField) []*math.PrimeField
+
+	// Assertions returns the boundary constraints of the segment, columns are indexed within the segment
+	Assertions(challenges []*math.PrimeField) []Assertion
+}
+
+// AuxiliaryAIR is an AIR with auxiliary trace segments
+type AuxiliaryAIR interface {
+	AIR
+
+	// AuxiliarySegments returns the auxiliary segments in the order they are built
+	AuxiliarySegments() []AuxiliarySegment
+}
+
+// CheckSegment checks that the last of the segments, which follow the main trace, has the shape of the auxiliary segment
+// and satisfies its constraints with the challenges
+func CheckSegment(segment AuxiliarySegment, segments []ExecutionTrace, challenges []*math.PrimeField) error {
+	trace, aux := segments[0], segments[len(segments)-1]
+
+	if len(aux) != len(trace) {
+		return fmt.Errorf("auxiliary segment has %d rows, expected %d", len(aux), len(trace))
+	}
+
+	for i, row := range aux {
+		if row.Len() != segment.Width() {
+			return fmt.Errorf("auxiliary row %d has width %d, expected %d", i, row.Len(), segment.Width())
+		}
+	}
+
+	if err := CheckAssertions(aux, segment.Assertions(challenges)); err != nil {
+		return fmt.Errorf("auxiliary segment: %w", err)
+	}
+
+	err := CheckTransitions(aux, func(step int, _, _ []*math.PrimeField) []*math.PrimeField {
+		current := make([][]*math.PrimeField, len(segments))
+		next := make([][]*math.PrimeField, len(segments))
+		for k, s := range segments {
+			current[k], next[k] = s[step].Coefficients, s[step+1].Coefficients
+		}
+
+		return segment.Evaluate(current, next, challenges)
+	})
+	if err != nil {
+		return fmt.Errorf("auxiliary segment: %w", err)
+	}
+
+	return nil
+}
+
+// BuildSegments builds and checks every auxiliary segment of the AIR with the challenges of each segment
+// and returns the main trace followed by the segments
+func BuildSegments(a AuxiliaryAIR, trace ExecutionTrace, challenges [][]*math.PrimeField) ([]ExecutionTrace, error) {
+	segments := []ExecutionTrace{trace}
+
+	for k, segment := range a.AuxiliarySegments() {
+		segments = append(segments, segment.Build(segments, challenges[k]))
+
+		if err := CheckSegment(segment, segments, challenges[k]); err != nil {
+			return nil, fmt.Errorf("segment %d: %w", k+1, err)
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,47 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// Assertion is a boundary constraint requiring the trace to hold Value in Column at Step
+type Assertion struct {
+	Column int
+	Step   int
+	Value  *math.PrimeField
+}
+
+// TransitionFunc evaluates transition constraints on two consecutive rows,
+// every returned value must be zero for a valid trace
+type TransitionFunc func(step int, current, next []*math.PrimeField) []*math.PrimeField
+
+// CheckAssertions checks that the trace satisfies every assertion
+func CheckAssertions(trace ExecutionTrace, assertions []Assertion) error {
+	for _, a := range assertions {
+		if a.Step < 0 || a.Step >= len(trace) || a.Column < 0 || a.Column >= trace[a.Step].Len() {
+			return fmt.Errorf("assertion at step %d column %d is outside of the trace", a.Step, a.Column)
+		}
+
+		if !trace[a.Step].At(a.Column).Equals(a.Value) {
+			return fmt.Errorf("assertion failed at step %d column %d: expected %v, got %v",
+				a.Step, a.Column, a.Value, trace[a.Step].At(a.Column))
+		}
+	}
+
+	return nil
+}
+
+// CheckTransitions checks that every pair of consecutive rows satisfies the transition constraints
+func CheckTransitions(trace ExecutionTrace, transition TransitionFunc) error {
+	for step := 0; step+1 < len(trace); step++ {
+		for i, value := range transition(step, trace[step].Coefficients, trace[step+1].Coefficients) {
+			if !value.IsZero() {
+				return fmt.Errorf("transition constraint %d failed at step %d: %v", i, step, value)
+			}
+		}
+	}
+
+	return nil
+}
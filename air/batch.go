@@ -0,0 +1,221 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// BatchBoundary is the column of the batch trace that flags the summary row closing every receipt,
+// the columns before it are the columns of the receipt trace
+const BatchBoundary = 2
+
+// batchColumns is the width of the batch trace
+const batchColumns = BatchBoundary + 1
+
+// ReceiptBatch is the trace of many receipts proven at once. Every receipt is laid out like a Receipt:
+// a row per price with the running sum, then a summary row holding the total in both columns,
+// which is flagged as a receipt boundary and resets the running sum for the next receipt.
+type ReceiptBatch struct {
+	First    []*math.PrimeField
+	Second   []*math.PrimeField
+	Boundary []*math.PrimeField
+
+	// Totals are the totals of the receipts in order
+	Totals []*math.PrimeField
+}
+
+// ComputeBatch lays out the receipts one after another, the last receipt is padded with zero prices,
+// so that the trace length is a power of two
+func ComputeBatch(receipts [][]*math.PrimeField) (*ReceiptBatch, error) {
+	if len(receipts) == 0 {
+		return nil, fmt.Errorf("batch has no receipts")
+	}
+
+	rows := 0
+	for _, prices := range receipts {
+		rows += len(prices) + 1
+	}
+
+	padding := 0
+	for rows < 2 || rows&(rows-1) != 0 {
+		rows++
+		padding++
+	}
+
+	b := &ReceiptBatch{
+		First:    make([]*math.PrimeField, 0, rows),
+		Second:   make([]*math.PrimeField, 0, rows),
+		Boundary: make([]*math.PrimeField, 0, rows),
+		Totals:   make([]*math.PrimeField, 0, len(receipts)),
+	}
+
+	for k, prices := range receipts {
+		if k == len(receipts)-1 {
+			prices = append(append([]*math.PrimeField{}, prices...), make([]*math.PrimeField, padding)...)
+		}
+
+		sum := new(math.PrimeField).SetZero()
+		for _, price := range prices {
+			if price == nil {
+				price = new(math.PrimeField).SetZero()
+			}
+
+			b.First = append(b.First, price)
+			b.Second = append(b.Second, sum)
+			b.Boundary = append(b.Boundary, new(math.PrimeField).SetZero())
+
+			sum = new(math.PrimeField).Add(sum, price)
+		}
+
+		b.First = append(b.First, sum)
+		b.Second = append(b.Second, sum)
+		b.Boundary = append(b.Boundary, new(math.PrimeField).SetOne())
+		b.Totals = append(b.Totals, sum)
+	}
+
+	return b, nil
+}
+
+// Trace returns the rows (price, running sum, boundary flag)
+func (b *ReceiptBatch) Trace() ExecutionTrace {
+	trace := make(ExecutionTrace, len(b.First))
+	for i := range trace {
+		trace[i] = math.NewPolynom([]*math.PrimeField{b.First[i], b.Second[i], b.Boundary[i]})
+	}
+
+	return trace
+}
+
+// AIR returns the public statement of the batch: its trace length and the total of every receipt
+func (b *ReceiptBatch) AIR() *BatchAIR {
+	return &BatchAIR{Steps: len(b.First), Totals: b.Totals}
+}
+
+// BatchAIR is the statement that a trace of Steps rows holds receipts with the given totals in order,
+// there must be at least one total.
+//
+// The main constraints are those of ReceiptAIR with a reset on boundaries: S' = (1 - B)·(F + S), B·(F - S) = 0
+// and B·(B - 1) = 0. The totals are bound by an auxiliary segment that folds the totals of all summary rows
+// but the last one with challenges (γ, β) into H = Σ γ^(K-2-k)·(β - t_k), which the verifier recomputes
+// from the public totals, while the last receipt ends on the last row and is asserted directly.
+// The totals are public inputs, so the transcript binds them before (γ, β) are drawn.
+// Folding β - t_k instead of t_k makes every term non-zero, so the number of receipts is bound as well.
+type BatchAIR struct {
+	Steps  int
+	Totals []*math.PrimeField
+}
+
+func (a *BatchAIR) TraceLength() int {
+	return a.Steps
+}
+
+func (a *BatchAIR) TraceWidth() int {
+	return batchColumns
+}
+
+func (a *BatchAIR) TransitionDegree() int {
+	return 2
+}
+
+func (a *BatchAIR) NumTransitionConstraints() int {
+	return 3
+}
+
+// EvaluateTransition resets the running sum after a boundary and requires a boundary row to hold the total
+func (a *BatchAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	boundary := current[BatchBoundary]
+	open := new(math.PrimeField).Sub(math.NewPrimeField(1), boundary)
+
+	sum := new(math.PrimeField).Mul(open, new(math.PrimeField).Add(current[0], current[1]))
+
+	return []*math.PrimeField{
+		new(math.PrimeField).Sub(next[1], sum),
+		new(math.PrimeField).Mul(boundary, new(math.PrimeField).Sub(current[0], current[1])),
+		new(math.PrimeField).Mul(boundary, new(math.PrimeField).Sub(boundary, math.NewPrimeField(1))),
+	}
+}
+
+// Assertions start the running sum at zero and close the last receipt on the last row
+func (a *BatchAIR) Assertions() []Assertion {
+	last := a.Totals[len(a.Totals)-1]
+
+	return []Assertion{
+		{Column: 1, Step: 0, Value: new(math.PrimeField).SetZero()},
+		{Column: 0, Step: a.Steps - 1, Value: last},
+		{Column: 1, Step: a.Steps - 1, Value: last},
+		{Column: BatchBoundary, Step: a.Steps - 1, Value: new(math.PrimeField).SetOne()},
+	}
+}
+
+// PublicInputs returns every total, which binds the challenges of the auxiliary segment to the totals:
+// with challenges drawn before the totals are fixed, totals that keep H would be accepted
+func (a *BatchAIR) PublicInputs() []*math.PrimeField {
+	return a.Totals
+}
+
+// AuxiliarySegments returns the fingerprint of the receipt totals
+func (a *BatchAIR) AuxiliarySegments() []AuxiliarySegment {
+	return []AuxiliarySegment{batchTotals{steps: a.Steps, totals: a.Totals}}
+}
+
+// batchTotals is the auxiliary segment folding the totals of the summary rows before the last row
+type batchTotals struct {
+	steps  int
+	totals []*math.PrimeField
+}
+
+func (s batchTotals) Width() int {
+	return 1
+}
+
+func (s batchTotals) NumChallenges() int {
+	return 2
+}
+
+// fold returns H' = H + B·((γ - 1)·H + β - F), which is γ·H + β - F on boundary rows and H elsewhere
+func (s batchTotals) fold(h *math.PrimeField, row []*math.PrimeField, challenges []*math.PrimeField) *math.PrimeField {
+	gamma, beta := challenges[0], challenges[1]
+
+	term := new(math.PrimeField).Mul(new(math.PrimeField).Sub(gamma, math.NewPrimeField(1)), h)
+	term = new(math.PrimeField).Add(term, new(math.PrimeField).Sub(beta, row[0]))
+
+	return new(math.PrimeField).Add(h, new(math.PrimeField).Mul(row[BatchBoundary], term))
+}
+
+func (s batchTotals) Build(segments []ExecutionTrace, challenges []*math.PrimeField) ExecutionTrace {
+	trace := segments[0]
+
+	column := make([]*math.PrimeField, len(trace))
+	column[0] = new(math.PrimeField).SetZero()
+	for i := range len(trace) - 1 {
+		column[i+1] = s.fold(column[i], trace[i].Coefficients, challenges)
+	}
+
+	return columnTrace(column)
+}
+
+func (s batchTotals) NumConstraints() int {
+	return 1
+}
+
+func (s batchTotals) Evaluate(current, next [][]*math.PrimeField, challenges []*math.PrimeField) []*math.PrimeField {
+	h, hNext := current[len(current)-1][0], next[len(next)-1][0]
+
+	return []*math.PrimeField{new(math.PrimeField).Sub(hNext, s.fold(h, current[0], challenges))}
+}
+
+// Assertions start the fold at zero and end it with the fold of the public totals but the last one
+func (s batchTotals) Assertions(challenges []*math.PrimeField) []Assertion {
+	gamma, beta := challenges[0], challenges[1]
+
+	h := new(math.PrimeField).SetZero()
+	for _, total := range s.totals[:len(s.totals)-1] {
+		h = new(math.PrimeField).Add(new(math.PrimeField).Mul(gamma, h), new(math.PrimeField).Sub(beta, total))
+	}
+
+	return []Assertion{
+		{Column: 0, Step: 0, Value: new(math.PrimeField).SetZero()},
+		{Column: 0, Step: s.steps - 1, Value: h},
+	}
+}
@@ -0,0 +1,271 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// CatalogEntry is a product of a store catalog and its unit price
+type CatalogEntry struct {
+	SKU   *math.PrimeField
+	Price *math.PrimeField
+}
+
+// leaf returns the leaf of the entry in the catalog tree: the Rescue hash of (SKU, price)
+func (e CatalogEntry) leaf() merkle.Digest {
+	return rescue.Hasher{}.HashElements([]*math.PrimeField{e.SKU, e.Price})
+}
+
+// Catalog is a store catalog committed as a Rescue Merkle tree of (SKU, price) leaves
+type Catalog struct {
+	// Entries are the leaves of the tree, padded to a power of two with copies of the last entry
+	Entries []CatalogEntry
+
+	tree  *merkle.Tree
+	index map[uint64]int
+}
+
+// NewCatalog commits to the entries, every SKU must appear once
+func NewCatalog(entries []CatalogEntry) (*Catalog, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("catalog is empty")
+	}
+
+	index := make(map[uint64]int, len(entries))
+	for i, entry := range entries {
+		if _, ok := index[entry.SKU.Uint64()]; ok {
+			return nil, fmt.Errorf("duplicate SKU %v", entry.SKU)
+		}
+		index[entry.SKU.Uint64()] = i
+	}
+
+	padded := append([]CatalogEntry{}, entries...)
+	for len(padded)&(len(padded)-1) != 0 {
+		padded = append(padded, entries[len(entries)-1])
+	}
+
+	leaves := make([]merkle.Digest, len(padded))
+	for i, entry := range padded {
+		leaves[i] = entry.leaf()
+	}
+
+	tree, err := merkle.NewTree(rescue.Hasher{}, leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Catalog{Entries: padded, tree: tree, index: index}, nil
+}
+
+// Root returns the commitment to the catalog
+func (c *Catalog) Root() merkle.Digest {
+	return c.tree.Root()
+}
+
+// Depth returns the length of authentication paths in the catalog tree
+func (c *Catalog) Depth() int {
+	return c.tree.Depth()
+}
+
+// Lookup returns the index of the entry with the SKU
+func (c *Catalog) Lookup(sku *math.PrimeField) (int, bool) {
+	i, ok := c.index[sku.Uint64()]
+	return i, ok
+}
+
+// CatalogLine is a receipt line that refers to a catalog entry instead of stating its price
+type CatalogLine struct {
+	SKU      *math.PrimeField
+	Quantity *math.PrimeField
+}
+
+// CatalogReceipt is an itemized receipt whose unit prices are taken from a catalog
+type CatalogReceipt struct {
+	Receipt *ItemizedReceipt
+	Catalog *Catalog
+
+	// entries holds the catalog index of the line in every row of the trace
+	entries []int
+}
+
+// ComputeCatalogReceipt looks up the unit price of every line in the catalog and computes the receipt
+// padded with zero quantities of the first catalog entry, so that the trace length is a power of two
+func ComputeCatalogReceipt(catalog *Catalog, lines []CatalogLine) (*CatalogReceipt, error) {
+	items := make([]LineItem, 0, len(lines))
+	entries := make([]int, 0, len(lines)+1)
+
+	for i, line := range lines {
+		entry, ok := catalog.Lookup(line.SKU)
+		if !ok {
+			return nil, fmt.Errorf("line %d: SKU %v is not in the catalog", i, line.SKU)
+		}
+
+		items = append(items, LineItem{Quantity: line.Quantity, UnitPrice: catalog.Entries[entry].Price})
+		entries = append(entries, entry)
+	}
+
+	for len(items) < 1 || (len(items)+1)&len(items) != 0 {
+		items = append(items, LineItem{Quantity: new(math.PrimeField).SetZero(), UnitPrice: catalog.Entries[0].Price})
+		entries = append(entries, 0)
+	}
+
+	// The last row holds the grand total, its catalog columns are not constrained
+	entries = append(entries, 0)
+
+	return &CatalogReceipt{
+		Receipt: ComputeItems(items),
+		Catalog: catalog,
+		entries: entries,
+	}, nil
+}
+
+// Columns of the catalog receipt trace after the itemized receipt columns:
+// the SKU, the permutation hashing the leaf and, for every level of the tree,
+// the direction bit, the sibling digest and the permutation merging both
+const (
+	catalogSKU    = itemizedWidth
+	catalogLeaf   = catalogSKU + 1
+	catalogLevels = catalogLeaf + RescueCheckColumns
+
+	levelBit         = 0
+	levelSibling     = 1
+	levelPermutation = levelSibling + rescue.DigestElements
+	levelColumns     = levelPermutation + RescueCheckColumns
+)
+
+var catalogLeafCheck = RescueCheck{Offset: catalogLeaf}
+
+// levelOffset returns the first column of a tree level
+func levelOffset(level int) int {
+	return catalogLevels + level*levelColumns
+}
+
+// Trace returns the itemized receipt trace extended with the authentication path of every line
+func (r *CatalogReceipt) Trace() ExecutionTrace {
+	trace := r.Receipt.Trace()
+
+	for i, row := range trace {
+		index := r.entries[i]
+		entry := r.Catalog.Entries[index]
+
+		input := rescue.InitialState(2)
+		input[0] = entry.SKU
+		input[1] = entry.Price
+
+		row.Coefficients = append(row.Coefficients, entry.SKU)
+		row.Coefficients = append(row.Coefficients, catalogLeafCheck.States(input)...)
+
+		node := catalogLeafCheck.Output(row.Coefficients)[:rescue.DigestElements]
+		for level, sibling := range r.Catalog.tree.Open(index) {
+			siblingElements := merkle.DigestToElements(sibling)
+			bit := math.NewPrimeFieldUint64(uint64(index>>level) & 1)
+
+			left, right := node, siblingElements
+			if index>>level&1 == 1 {
+				left, right = right, left
+			}
+
+			input = rescue.NodeState()
+			copy(input, left)
+			copy(input[rescue.DigestElements:], right)
+
+			check := RescueCheck{Offset: levelOffset(level) + levelPermutation}
+
+			row.Coefficients = append(row.Coefficients, bit)
+			row.Coefficients = append(row.Coefficients, siblingElements...)
+			row.Coefficients = append(row.Coefficients, check.States(input)...)
+
+			node = check.Output(row.Coefficients)[:rescue.DigestElements]
+		}
+	}
+
+	return trace
+}
+
+// AIR returns the public statement of the receipt: the catalog root and the grand total
+func (r *CatalogReceipt) AIR() *CatalogAIR {
+	return &CatalogAIR{
+		ItemizedAIR: r.Receipt.AIR(),
+		Root:        r.Catalog.Root(),
+		Depth:       r.Catalog.Depth(),
+	}
+}
+
+// CatalogAIR is the statement of an itemized receipt whose every (SKU, unit price) pair
+// is a leaf of the catalog tree with the public root
+type CatalogAIR struct {
+	*ItemizedAIR
+	Root  merkle.Digest
+	Depth int
+}
+
+// PublicInputs returns the catalog root and the depth of the tree, which the constraints read
+func (a *CatalogAIR) PublicInputs() []*math.PrimeField {
+	return append(merkle.DigestToElements(a.Root), math.NewPrimeField(int64(a.Depth)))
+}
+
+func (a *CatalogAIR) TraceWidth() int {
+	return levelOffset(a.Depth)
+}
+
+func (a *CatalogAIR) TransitionDegree() int {
+	return rescue.Alpha
+}
+
+func (a *CatalogAIR) NumTransitionConstraints() int {
+	hash := rescue.Width + catalogLeafCheck.NumConstraints()
+	return a.ItemizedAIR.NumTransitionConstraints() + hash + a.Depth*(1+hash) + rescue.DigestElements
+}
+
+// EvaluateTransition evaluates the itemized receipt constraints and recomputes the catalog root
+// from the SKU, the unit price and the authentication path of the current line
+func (a *CatalogAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	res := a.ItemizedAIR.EvaluateTransition(current, next)
+
+	leaf := rescue.InitialState(2)
+	leaf[0] = current[catalogSKU]
+	leaf[1] = current[ItemUnitPrice]
+
+	res = append(res, sub(catalogLeafCheck.Input(current), leaf)...)
+	res = append(res, catalogLeafCheck.Evaluate(current)...)
+
+	one := math.NewPrimeField(1)
+	node := catalogLeafCheck.Output(current)[:rescue.DigestElements]
+
+	for level := range a.Depth {
+		offset := levelOffset(level)
+		bit := current[offset+levelBit]
+		sibling := current[offset+levelSibling : offset+levelSibling+rescue.DigestElements]
+		check := RescueCheck{Offset: offset + levelPermutation}
+
+		// left = node + b·(sibling - node), right = sibling + b·(node - sibling)
+		input := rescue.NodeState()
+		for i := range rescue.DigestElements {
+			swap := new(math.PrimeField).Mul(bit, new(math.PrimeField).Sub(sibling[i], node[i]))
+
+			input[i] = new(math.PrimeField).Add(node[i], swap)
+			input[rescue.DigestElements+i] = new(math.PrimeField).Sub(sibling[i], swap)
+		}
+
+		res = append(res, new(math.PrimeField).Mul(bit, new(math.PrimeField).Sub(bit, one)))
+		res = append(res, sub(check.Input(current), input)...)
+		res = append(res, check.Evaluate(current)...)
+
+		node = check.Output(current)[:rescue.DigestElements]
+	}
+
+	return append(res, sub(node, merkle.DigestToElements(a.Root))...)
+}
+
+// sub returns the element-wise difference of two vectors
+func sub(a, b []*math.PrimeField) []*math.PrimeField {
+	res := make([]*math.PrimeField, len(a))
+	for i := range a {
+		res[i] = new(math.PrimeField).Sub(a[i], b[i])
+	}
+
+	return res
+}
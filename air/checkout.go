@@ -0,0 +1,279 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// BasisPoints is the denominator of discount and tax rates, 1% is 100 basis points
+const BasisPoints = 10000
+
+// remainderBits is the bit size of rounding remainders, which are below BasisPoints
+const remainderBits = 14
+
+// PricingRules are the public rules applied to the subtotal of a receipt
+type PricingRules struct {
+	// DiscountBasisPoints is the percentage discount on the subtotal, rounded down
+	DiscountBasisPoints uint64
+
+	// Coupon is a fixed discount in minor units applied after the percentage discount
+	Coupon uint64
+
+	// TaxBasisPoints is the tax rate on the discounted subtotal, rounded half up
+	TaxBasisPoints uint64
+}
+
+// Validate checks that the rates are at most 100%
+func (r PricingRules) Validate() error {
+	if r.DiscountBasisPoints > BasisPoints {
+		return fmt.Errorf("discount of %d basis points exceeds %d", r.DiscountBasisPoints, BasisPoints)
+	}
+
+	if r.TaxBasisPoints > BasisPoints {
+		return fmt.Errorf("tax of %d basis points exceeds %d", r.TaxBasisPoints, BasisPoints)
+	}
+
+	if r.Coupon >= 1<<RangeBits {
+		return fmt.Errorf("coupon %d does not fit in %d bits", r.Coupon, RangeBits)
+	}
+
+	return nil
+}
+
+// Summary is the public output of a checkout
+type Summary struct {
+	Subtotal *math.PrimeField
+	Discount *math.PrimeField
+	Tax      *math.PrimeField
+	Total    *math.PrimeField
+}
+
+// Checkout is an itemized receipt with pricing rules applied to its subtotal:
+//
+//	discount = ⌊subtotal · DiscountBasisPoints / 10000⌋ + Coupon
+//	tax      = ⌊((subtotal - discount) · TaxBasisPoints + 5000) / 10000⌋
+//	total    = subtotal - discount + tax
+//
+// Each division is proven with a quotient and a remainder in [0, 10000) held in witness columns.
+type Checkout struct {
+	Receipt *ItemizedReceipt
+	Rules   PricingRules
+	Summary Summary
+
+	// witness holds the values of the checkout columns, they are the same in every row
+	witness []*math.PrimeField
+}
+
+// Columns of the checkout trace after the itemized receipt columns
+const (
+	checkoutSubtotal = itemizedWidth + iota
+	checkoutPercent
+	checkoutPercentRemainder
+	checkoutPercentGap
+	checkoutDiscount
+	checkoutBase
+	checkoutTax
+	checkoutTaxRemainder
+	checkoutTaxGap
+	checkoutTotal
+
+	checkoutColumns
+)
+
+// checkoutChecks bound the subtotal and the discounted subtotal to 32 bits,
+// which keeps every product with a rate below the modulus and the discount below the subtotal,
+// and bound the remainders r and their gaps 9999 - r to 14 bits, so that 0 ≤ r < 10000.
+// The percentage discount, the tax and the total are bound to 32 bits as well: a quotient is only unique
+// together with its remainder if both sides of the division stay below the modulus, otherwise another
+// remainder in range and the quotient shifted by a multiple of 10000^-1 satisfy the same relation.
+var checkoutChecks = []RangeCheck{
+	{Column: checkoutSubtotal, Offset: checkoutColumns, Bits: RangeBits},
+	{Column: checkoutBase, Offset: checkoutColumns + RangeBits, Bits: RangeBits},
+	{Column: checkoutPercentRemainder, Offset: checkoutColumns + 2*RangeBits, Bits: remainderBits},
+	{Column: checkoutPercentGap, Offset: checkoutColumns + 2*RangeBits + remainderBits, Bits: remainderBits},
+	{Column: checkoutTaxRemainder, Offset: checkoutColumns + 2*RangeBits + 2*remainderBits, Bits: remainderBits},
+	{Column: checkoutTaxGap, Offset: checkoutColumns + 2*RangeBits + 3*remainderBits, Bits: remainderBits},
+	{Column: checkoutPercent, Offset: checkoutColumns + 2*RangeBits + 4*remainderBits, Bits: RangeBits},
+	{Column: checkoutTax, Offset: checkoutColumns + 3*RangeBits + 4*remainderBits, Bits: RangeBits},
+	{Column: checkoutTotal, Offset: checkoutColumns + 4*RangeBits + 4*remainderBits, Bits: RangeBits},
+}
+
+const checkoutWidth = checkoutColumns + 5*RangeBits + 4*remainderBits
+
+// ComputeCheckout computes the receipt of line items padded to a power of two and applies the pricing rules
+func ComputeCheckout(items []LineItem, rules PricingRules) (*Checkout, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	receipt := ComputeItemsPadded(items)
+
+	subtotal := receipt.Total().Uint64()
+	if subtotal >= 1<<RangeBits {
+		return nil, fmt.Errorf("subtotal %d does not fit in %d bits", subtotal, RangeBits)
+	}
+
+	percent := subtotal * rules.DiscountBasisPoints / BasisPoints
+	percentRemainder := subtotal * rules.DiscountBasisPoints % BasisPoints
+
+	if percent+rules.Coupon > subtotal {
+		return nil, fmt.Errorf("discount %d exceeds subtotal %d", percent+rules.Coupon, subtotal)
+	}
+
+	discount := percent + rules.Coupon
+	base := subtotal - discount
+
+	tax := (base*rules.TaxBasisPoints + BasisPoints/2) / BasisPoints
+	taxRemainder := (base*rules.TaxBasisPoints + BasisPoints/2) % BasisPoints
+
+	if base+tax >= 1<<RangeBits {
+		return nil, fmt.Errorf("total %d does not fit in %d bits", base+tax, RangeBits)
+	}
+
+	values := []uint64{
+		subtotal,
+		percent,
+		percentRemainder,
+		BasisPoints - 1 - percentRemainder,
+		discount,
+		base,
+		tax,
+		taxRemainder,
+		BasisPoints - 1 - taxRemainder,
+		base + tax,
+	}
+
+	witness := make([]*math.PrimeField, len(values))
+	for i, value := range values {
+		witness[i] = math.NewPrimeFieldUint64(value)
+	}
+
+	return &Checkout{
+		Receipt: receipt,
+		Rules:   rules,
+		Summary: Summary{
+			Subtotal: witness[checkoutSubtotal-itemizedWidth],
+			Discount: witness[checkoutDiscount-itemizedWidth],
+			Tax:      witness[checkoutTax-itemizedWidth],
+			Total:    witness[checkoutTotal-itemizedWidth],
+		},
+		witness: witness,
+	}, nil
+}
+
+// Trace returns the itemized receipt trace extended with the checkout columns and their range checks
+func (c *Checkout) Trace() ExecutionTrace {
+	trace := c.Receipt.Trace()
+
+	for _, row := range trace {
+		row.Coefficients = append(row.Coefficients, c.witness...)
+
+		for _, check := range checkoutChecks {
+			row.Coefficients = append(row.Coefficients, check.Decompose(row.Coefficients[check.Column])...)
+		}
+	}
+
+	return trace
+}
+
+// AIR returns the public statement of the checkout
+func (c *Checkout) AIR() *CheckoutAIR {
+	return &CheckoutAIR{
+		ItemizedAIR: &ItemizedAIR{Steps: c.Receipt.AIR().Steps, Total: c.Summary.Subtotal},
+		Rules:       c.Rules,
+		Summary:     c.Summary,
+	}
+}
+
+// CheckoutAIR is the public statement of a checkout: the trace length, the pricing rules and the summary.
+// The embedded itemized statement holds the subtotal as its grand total.
+type CheckoutAIR struct {
+	*ItemizedAIR
+	Rules   PricingRules
+	Summary Summary
+}
+
+// PublicInputs returns the pricing rules, which the constraints read
+func (a *CheckoutAIR) PublicInputs() []*math.PrimeField {
+	return []*math.PrimeField{
+		math.NewPrimeFieldUint64(a.Rules.DiscountBasisPoints),
+		math.NewPrimeFieldUint64(a.Rules.Coupon),
+		math.NewPrimeFieldUint64(a.Rules.TaxBasisPoints),
+	}
+}
+
+func (a *CheckoutAIR) TraceWidth() int {
+	return checkoutWidth
+}
+
+func (a *CheckoutAIR) NumTransitionConstraints() int {
+	n := a.ItemizedAIR.NumTransitionConstraints() + (checkoutColumns - checkoutSubtotal) + 7
+	for _, check := range checkoutChecks {
+		n += check.NumConstraints()
+	}
+
+	return n
+}
+
+// EvaluateTransition evaluates the itemized receipt constraints, keeps the checkout columns constant
+// and checks the pricing relations between them on every row
+func (a *CheckoutAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	res := a.ItemizedAIR.EvaluateTransition(current, next)
+
+	for i := checkoutSubtotal; i < checkoutColumns; i++ {
+		res = append(res, new(math.PrimeField).Sub(next[i], current[i]))
+	}
+
+	basisPoints := math.NewPrimeField(BasisPoints)
+	maxRemainder := math.NewPrimeField(BasisPoints - 1)
+
+	// subtotal · rate - 10000 · percent - remainder
+	percent := new(math.PrimeField).Mul(current[checkoutSubtotal], math.NewPrimeFieldUint64(a.Rules.DiscountBasisPoints))
+	percent.Sub(percent, new(math.PrimeField).Mul(basisPoints, current[checkoutPercent]))
+	percent.Sub(percent, current[checkoutPercentRemainder])
+
+	// base · rate + 5000 - 10000 · tax - remainder
+	tax := new(math.PrimeField).Mul(current[checkoutBase], math.NewPrimeFieldUint64(a.Rules.TaxBasisPoints))
+	tax.Add(tax, math.NewPrimeField(BasisPoints/2))
+	tax.Sub(tax, new(math.PrimeField).Mul(basisPoints, current[checkoutTax]))
+	tax.Sub(tax, current[checkoutTaxRemainder])
+
+	// discount - percent - coupon
+	discount := new(math.PrimeField).Sub(current[checkoutDiscount], current[checkoutPercent])
+	discount.Sub(discount, math.NewPrimeFieldUint64(a.Rules.Coupon))
+
+	// base - subtotal + discount
+	base := new(math.PrimeField).Sub(current[checkoutBase], current[checkoutSubtotal])
+	base.Add(base, current[checkoutDiscount])
+
+	// total - base - tax
+	total := new(math.PrimeField).Sub(current[checkoutTotal], current[checkoutBase])
+	total.Sub(total, current[checkoutTax])
+
+	res = append(res,
+		percent,
+		new(math.PrimeField).Sub(new(math.PrimeField).Add(current[checkoutPercentRemainder], current[checkoutPercentGap]), maxRemainder),
+		discount,
+		base,
+		tax,
+		new(math.PrimeField).Sub(new(math.PrimeField).Add(current[checkoutTaxRemainder], current[checkoutTaxGap]), maxRemainder),
+		total,
+	)
+
+	for _, check := range checkoutChecks {
+		res = append(res, check.Evaluate(current)...)
+	}
+
+	return res
+}
+
+// Assertions bind the running sum to the subtotal and the constant checkout columns to the summary
+func (a *CheckoutAIR) Assertions() []Assertion {
+	return append(a.ItemizedAIR.Assertions(),
+		Assertion{Column: checkoutSubtotal, Step: 0, Value: a.Summary.Subtotal},
+		Assertion{Column: checkoutDiscount, Step: 0, Value: a.Summary.Discount},
+		Assertion{Column: checkoutTax, Step: 0, Value: a.Summary.Tax},
+		Assertion{Column: checkoutTotal, Step: 0, Value: a.Summary.Total},
+	)
+}
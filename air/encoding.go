@@ -0,0 +1,221 @@
+package air
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// receiptJSON is the JSON layout of a receipt
+type receiptJSON struct {
+	First  []*math.PrimeField `json:"first"`
+	Second []*math.PrimeField `json:"second"`
+}
+
+// MarshalBinary encodes the receipt as the length-prefixed First column
+// followed by the length-prefixed Second column
+func (r *Receipt) MarshalBinary() ([]byte, error) {
+	out := math.AppendElements(nil, r.First)
+	return math.AppendElements(out, r.Second), nil
+}
+
+// UnmarshalBinary decodes a receipt written by MarshalBinary
+func (r *Receipt) UnmarshalBinary(data []byte) error {
+	first, rest, err := math.ReadElements(data)
+	if err != nil {
+		return fmt.Errorf("decode receipt first column: %w", err)
+	}
+
+	second, rest, err := math.ReadElements(rest)
+	if err != nil {
+		return fmt.Errorf("decode receipt second column: %w", err)
+	}
+
+	if len(rest) != 0 {
+		return fmt.Errorf("decode receipt: %w", math.ErrTrailingBytes)
+	}
+
+	return r.set(first, second)
+}
+
+// MarshalText encodes the receipt as the hex string of its binary encoding
+func (r *Receipt) MarshalText() ([]byte, error) {
+	data, _ := r.MarshalBinary()
+	return []byte(hex.EncodeToString(data)), nil
+}
+
+// UnmarshalText decodes a receipt written by MarshalText
+func (r *Receipt) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("decode receipt: %w", err)
+	}
+
+	return r.UnmarshalBinary(data)
+}
+
+// MarshalJSON encodes the receipt as {"first": [...], "second": [...]} with decimal string elements
+func (r *Receipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(receiptJSON{First: r.First, Second: r.Second})
+}
+
+// UnmarshalJSON decodes a receipt written by MarshalJSON
+func (r *Receipt) UnmarshalJSON(data []byte) error {
+	var decoded receiptJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("decode receipt: %w", err)
+	}
+
+	for i := range decoded.First {
+		if decoded.First[i] == nil {
+			return fmt.Errorf("decode receipt: first[%d] is null", i)
+		}
+	}
+
+	for i := range decoded.Second {
+		if decoded.Second[i] == nil {
+			return fmt.Errorf("decode receipt: second[%d] is null", i)
+		}
+	}
+
+	return r.set(decoded.First, decoded.Second)
+}
+
+func (r *Receipt) set(first, second []*math.PrimeField) error {
+	if len(first) != len(second) {
+		return fmt.Errorf("decode receipt: columns have different lengths %d and %d", len(first), len(second))
+	}
+
+	r.First = first
+	r.Second = second
+
+	return nil
+}
+
+// Width returns the number of registers in a row, 0 for an empty trace
+func (t ExecutionTrace) Width() int {
+	if len(t) == 0 {
+		return 0
+	}
+
+	return t[0].Len()
+}
+
+// MarshalBinary encodes the trace as a little-endian uint32 number of rows,
+// a little-endian uint32 width and the canonical encoding of every row in order
+func (t ExecutionTrace) MarshalBinary() ([]byte, error) {
+	width := t.Width()
+
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(t)))
+	out = binary.LittleEndian.AppendUint32(out, uint32(width))
+
+	for i, row := range t {
+		if row.Len() != width {
+			return nil, fmt.Errorf("encode trace: row %d has width %d, expected %d", i, row.Len(), width)
+		}
+
+		for _, element := range row.Coefficients {
+			if element == nil {
+				element = new(math.PrimeField)
+			}
+			out = element.AppendBinary(out)
+		}
+	}
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes a trace written by MarshalBinary.
+// The rows are allocated only for the elements present in data, so a trace of empty rows is rejected.
+func (t *ExecutionTrace) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("decode trace: missing header")
+	}
+
+	rows := uint64(binary.LittleEndian.Uint32(data))
+	width := uint64(binary.LittleEndian.Uint32(data[4:]))
+	data = data[8:]
+
+	if rows != 0 && width == 0 {
+		return fmt.Errorf("decode trace: %d rows of width 0", rows)
+	}
+
+	// rows and width fit in 32 bits, only the size in bytes can overflow
+	hi, size := bits.Mul64(rows*width, math.PrimeFieldBytes)
+	if hi != 0 || uint64(len(data)) != size {
+		return fmt.Errorf("decode trace: expected %d elements, got %d bytes", rows*width, len(data))
+	}
+
+	trace := make(ExecutionTrace, rows)
+	for i := range trace {
+		row := make([]*math.PrimeField, width)
+		for j := range row {
+			row[j] = new(math.PrimeField)
+			if err := row[j].UnmarshalBinary(data[:math.PrimeFieldBytes]); err != nil {
+				return fmt.Errorf("decode trace: row %d column %d: %w", i, j, err)
+			}
+			data = data[math.PrimeFieldBytes:]
+		}
+		trace[i] = math.NewPolynom(row)
+	}
+
+	*t = trace
+	return nil
+}
+
+// MarshalText encodes the trace as the hex string of its binary encoding
+func (t ExecutionTrace) MarshalText() ([]byte, error) {
+	data, err := t.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(data)), nil
+}
+
+// UnmarshalText decodes a trace written by MarshalText
+func (t *ExecutionTrace) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("decode trace: %w", err)
+	}
+
+	return t.UnmarshalBinary(data)
+}
+
+// MarshalJSON encodes the trace as an array of rows of decimal strings
+func (t ExecutionTrace) MarshalJSON() ([]byte, error) {
+	width := t.Width()
+	for i, row := range t {
+		if row.Len() != width {
+			return nil, fmt.Errorf("encode trace: row %d has width %d, expected %d", i, row.Len(), width)
+		}
+	}
+
+	return json.Marshal([]*math.Polynom(t))
+}
+
+// UnmarshalJSON decodes a trace written by MarshalJSON
+func (t *ExecutionTrace) UnmarshalJSON(data []byte) error {
+	var rows []*math.Polynom
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return fmt.Errorf("decode trace: %w", err)
+	}
+
+	for i, row := range rows {
+		if row == nil {
+			return fmt.Errorf("decode trace: row %d is null", i)
+		}
+
+		if row.Len() != rows[0].Len() {
+			return fmt.Errorf("decode trace: row %d has width %d, expected %d", i, row.Len(), rows[0].Len())
+		}
+	}
+
+	*t = rows
+	return nil
+}
@@ -0,0 +1,171 @@
+package air
+
+import (
+	"github.com/KyrylR/simple-air/math"
+)
+
+// LineItem is a single receipt line: a quantity of units sold at a unit price
+type LineItem struct {
+	Quantity  *math.PrimeField
+	UnitPrice *math.PrimeField
+}
+
+// NewLineItem returns a line item of quantity units at the unit price
+func NewLineItem(quantity, unitPrice int64) LineItem {
+	return LineItem{
+		Quantity:  math.NewPrimeField(quantity),
+		UnitPrice: math.NewPrimeField(unitPrice),
+	}
+}
+
+// ItemizedReceipt is the execution trace of a receipt of line items, column by column.
+// Row i holds the line i and the running sum of the lines before it,
+// the last row holds an empty line and the grand total.
+type ItemizedReceipt struct {
+	Quantities []*math.PrimeField
+	UnitPrices []*math.PrimeField
+	LineTotals []*math.PrimeField
+	Sums       []*math.PrimeField
+}
+
+// ComputeItems computes the trace of a receipt of line items
+func ComputeItems(items []LineItem) *ItemizedReceipt {
+	n := len(items) + 1
+
+	r := &ItemizedReceipt{
+		Quantities: make([]*math.PrimeField, n),
+		UnitPrices: make([]*math.PrimeField, n),
+		LineTotals: make([]*math.PrimeField, n),
+		Sums:       make([]*math.PrimeField, n),
+	}
+
+	r.Sums[0] = new(math.PrimeField).SetZero()
+
+	for i, item := range items {
+		r.Quantities[i] = item.Quantity
+		r.UnitPrices[i] = item.UnitPrice
+		r.LineTotals[i] = new(math.PrimeField).Mul(item.Quantity, item.UnitPrice)
+		r.Sums[i+1] = new(math.PrimeField).Add(r.Sums[i], r.LineTotals[i])
+	}
+
+	r.Quantities[n-1] = new(math.PrimeField).SetZero()
+	r.UnitPrices[n-1] = new(math.PrimeField).SetZero()
+	r.LineTotals[n-1] = new(math.PrimeField).SetZero()
+
+	return r
+}
+
+// ComputeItemsPadded computes the receipt of line items padded with empty lines,
+// so that the trace length is a power of two
+func ComputeItemsPadded(items []LineItem) *ItemizedReceipt {
+	padded := append([]LineItem{}, items...)
+	for len(padded) < 1 || (len(padded)+1)&len(padded) != 0 {
+		padded = append(padded, NewLineItem(0, 0))
+	}
+
+	return ComputeItems(padded)
+}
+
+// Total returns the grand total of the receipt
+func (r *ItemizedReceipt) Total() *math.PrimeField {
+	return r.Sums[len(r.Sums)-1]
+}
+
+// Trace returns the rows (quantity, unit price, line total, running sum)
+// followed by the binary decompositions of quantity, unit price and line total
+func (r *ItemizedReceipt) Trace() ExecutionTrace {
+	trace := make([]*math.Polynom, len(r.Sums))
+
+	for i := range r.Sums {
+		row := []*math.PrimeField{
+			r.Quantities[i],
+			r.UnitPrices[i],
+			r.LineTotals[i],
+			r.Sums[i],
+		}
+
+		for _, check := range itemChecks {
+			row = append(row, check.Decompose(row[check.Column])...)
+		}
+
+		trace[i] = &math.Polynom{Coefficients: row}
+	}
+
+	return trace
+}
+
+// AIR returns the public statement of the receipt
+func (r *ItemizedReceipt) AIR() *ItemizedAIR {
+	return &ItemizedAIR{
+		Steps: len(r.Sums),
+		Total: r.Total(),
+	}
+}
+
+// Columns of the itemized receipt trace
+const (
+	ItemQuantity = iota
+	ItemUnitPrice
+	ItemLineTotal
+	ItemSum
+
+	itemColumns
+	itemizedWidth = itemColumns + 3*RangeBits
+)
+
+// itemChecks range check quantity, unit price and line total of every line.
+// The product of a quantity and a unit price below 2^32 does not wrap, so the line total is exact,
+// and the sum of line totals below 2^32 does not wrap either.
+var itemChecks = []RangeCheck{
+	{Column: ItemQuantity, Offset: itemColumns, Bits: RangeBits},
+	{Column: ItemUnitPrice, Offset: itemColumns + RangeBits, Bits: RangeBits},
+	{Column: ItemLineTotal, Offset: itemColumns + 2*RangeBits, Bits: RangeBits},
+}
+
+// ItemizedAIR is the public statement of an itemized receipt: its trace length and grand total
+type ItemizedAIR struct {
+	Steps int
+	Total *math.PrimeField
+}
+
+func (a *ItemizedAIR) TraceLength() int {
+	return a.Steps
+}
+
+func (a *ItemizedAIR) TraceWidth() int {
+	return itemizedWidth
+}
+
+func (a *ItemizedAIR) TransitionDegree() int {
+	return 2
+}
+
+func (a *ItemizedAIR) NumTransitionConstraints() int {
+	return 2 + len(itemChecks)*(RangeBits+1)
+}
+
+// EvaluateTransition checks that the line total is the product of quantity and unit price,
+// that the running sum is updated with the line total: S' = S + T, and that the line amounts are below 2^32.
+// The last row is not constrained, its line does not contribute to the grand total.
+func (a *ItemizedAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	product := new(math.PrimeField).Mul(current[ItemQuantity], current[ItemUnitPrice])
+
+	res := []*math.PrimeField{
+		new(math.PrimeField).Sub(current[ItemLineTotal], product),
+		new(math.PrimeField).Sub(next[ItemSum], new(math.PrimeField).Add(current[ItemSum], current[ItemLineTotal])),
+	}
+
+	for _, check := range itemChecks {
+		res = append(res, check.Evaluate(current)...)
+	}
+
+	return res
+}
+
+// Assertions require the running sum to start at zero and to end with the grand total
+func (a *ItemizedAIR) Assertions() []Assertion {
+	return []Assertion{
+		{Column: ItemSum, Step: 0, Value: new(math.PrimeField).SetZero()},
+		{Column: ItemSum, Step: a.Steps - 1, Value: a.Total},
+	}
+}
@@ -0,0 +1,252 @@
+package air
+
+import (
+	"github.com/KyrylR/simple-air/math"
+)
+
+// LookupChallenges is the number of challenges of a LookupCheck
+const LookupChallenges = 2
+
+// LookupCheck is a LogUp argument that every tuple of the Values columns of the main trace over every step
+// except the last one is a row of the Table columns, where the Multiplicity column counts how often each table row
+// is looked up. It is an auxiliary segment for a trace of the given number of Steps.
+//
+// With challenges α and β a tuple is compressed into β - Σ α^i·x_i, then
+//
+//	Σ_k Σ_i 1 / (β - v_{i,k}) = Σ_k m_k / (β - t_k)
+//
+// holds if every value is in the table and, by the Schwartz-Zippel lemma, holds otherwise
+// with probability at most about (len(Values) + 1)·n / |F|.
+//
+// The segment has len(Values) + 2 columns: the inverse h_i = 1 / (β - v_i) of every looked-up tuple,
+// the table term g = m / (β - t) and the running sum S of Σ h_i - g.
+// Keeping the inverses in columns keeps every constraint at degree 2.
+type LookupCheck struct {
+	Values       [][]int
+	Table        []int
+	Multiplicity int
+	Steps        int
+}
+
+func (c LookupCheck) Width() int {
+	return len(c.Values) + 2
+}
+
+func (c LookupCheck) NumChallenges() int {
+	return LookupChallenges
+}
+
+// Build returns the segment of the main trace, all denominators are inverted at once with a batch inversion
+func (c LookupCheck) Build(segments []ExecutionTrace, challenges []*math.PrimeField) ExecutionTrace {
+	trace := segments[0]
+	steps := len(trace) - 1
+	tuples := len(c.Values)
+
+	denominators := make([]*math.PrimeField, 0, steps*(tuples+1))
+	for _, row := range trace[:steps] {
+		for _, columns := range c.Values {
+			denominators = append(denominators, compress(row.Coefficients, columns, challenges))
+		}
+		denominators = append(denominators, compress(row.Coefficients, c.Table, challenges))
+	}
+
+	inverses := new(math.PrimeField).MultiInv(denominators)
+
+	columns := make([][]*math.PrimeField, c.Width())
+	for j := range columns {
+		columns[j] = make([]*math.PrimeField, len(trace))
+	}
+
+	sum := new(math.PrimeField).SetZero()
+	for k, row := range trace[:steps] {
+		columns[tuples+1][k] = sum
+
+		next := sum
+		for i := range tuples {
+			columns[i][k] = inverses[k*(tuples+1)+i]
+			next = new(math.PrimeField).Add(next, columns[i][k])
+		}
+
+		columns[tuples][k] = new(math.PrimeField).Mul(row.At(c.Multiplicity), inverses[k*(tuples+1)+tuples])
+		sum = new(math.PrimeField).Sub(next, columns[tuples][k])
+	}
+
+	// The last row is not looked up, its inverses are left zero
+	for j := range tuples + 1 {
+		columns[j][steps] = new(math.PrimeField).SetZero()
+	}
+	columns[tuples+1][steps] = sum
+
+	return columnTrace(columns...)
+}
+
+func (c LookupCheck) NumConstraints() int {
+	return len(c.Values) + 2
+}
+
+// Evaluate evaluates the degree 2 constraints h_i·(β - v_i) = 1, g·(β - t) = m and S' = S + Σ h_i - g
+func (c LookupCheck) Evaluate(current, next [][]*math.PrimeField, challenges []*math.PrimeField) []*math.PrimeField {
+	main, aux, auxNext := current[0], current[len(current)-1], next[len(next)-1]
+	tuples := len(c.Values)
+	one := math.NewPrimeField(1)
+
+	res := make([]*math.PrimeField, 0, c.NumConstraints())
+
+	sum := new(math.PrimeField).Sub(auxNext[tuples+1], aux[tuples+1])
+	for i, columns := range c.Values {
+		res = append(res, new(math.PrimeField).Sub(new(math.PrimeField).Mul(aux[i], compress(main, columns, challenges)), one))
+		sum = new(math.PrimeField).Sub(sum, aux[i])
+	}
+
+	term := aux[tuples]
+	res = append(res, new(math.PrimeField).Sub(new(math.PrimeField).Mul(term, compress(main, c.Table, challenges)), main[c.Multiplicity]))
+
+	return append(res, new(math.PrimeField).Add(sum, term))
+}
+
+// Assertions require the running sum to start and end with 0
+func (c LookupCheck) Assertions(_ []*math.PrimeField) []Assertion {
+	column := len(c.Values) + 1
+
+	return []Assertion{
+		{Column: column, Step: 0, Value: new(math.PrimeField).SetZero()},
+		{Column: column, Step: c.Steps - 1, Value: new(math.PrimeField).SetZero()},
+	}
+}
+
+const (
+	// LookupLimbBits is the size of the limbs prices are split into for lookup range checks
+	LookupLimbBits = 8
+
+	// LookupRangeSteps is the minimal trace length of a lookup range-checked receipt:
+	// the table of 2^LookupLimbBits limb values must fit before the last row
+	LookupRangeSteps = 2 << LookupLimbBits
+
+	lookupLimbs = RangeBits / LookupLimbBits
+)
+
+// Columns of the lookup range-checked receipt trace after the receipt columns
+const (
+	lookupLimb         = 2
+	lookupTable        = lookupLimb + lookupLimbs
+	lookupMultiplicity = lookupTable + 1
+
+	lookupColumns = lookupMultiplicity + 1
+)
+
+// lookupRange returns the lookup of every limb into the limb table on a trace of the given length
+func lookupRange(steps int) LookupCheck {
+	c := LookupCheck{Table: []int{lookupTable}, Multiplicity: lookupMultiplicity, Steps: steps}
+	for i := range lookupLimbs {
+		c.Values = append(c.Values, []int{lookupLimb + i})
+	}
+
+	return c
+}
+
+// ComputeLookupPadded computes the receipt of prices padded with zero prices to a power of two
+// of at least LookupRangeSteps rows
+func ComputeLookupPadded(prices []*math.PrimeField) *Receipt {
+	padded := append([]*math.PrimeField{}, prices...)
+	for len(padded)+1 < LookupRangeSteps {
+		padded = append(padded, new(math.PrimeField).SetZero())
+	}
+
+	return ComputePadded(padded)
+}
+
+// LookupRangeCheckedTrace returns the receipt trace extended with the limbs of every price,
+// the limb table 0, 1, ..., 2^LookupLimbBits - 1, which repeats its last value until the end of the trace,
+// and the number of times every table value is used as a limb
+func (r *Receipt) LookupRangeCheckedTrace() ExecutionTrace {
+	trace := r.Trace()
+	tableSize := 1 << LookupLimbBits
+
+	multiplicities := make([]uint64, tableSize)
+	limbs := make([][]*math.PrimeField, len(trace))
+
+	for i := range trace {
+		value := r.First[i].Uint64()
+		if i == len(trace)-1 {
+			// The last row holds the total in place of a price
+			value = 0
+		}
+
+		limbs[i] = make([]*math.PrimeField, lookupLimbs)
+		for j := range limbs[i] {
+			limb := (value >> (j * LookupLimbBits)) & uint64(tableSize-1)
+			limbs[i][j] = math.NewPrimeFieldUint64(limb)
+
+			if i < len(trace)-1 {
+				multiplicities[limb]++
+			}
+		}
+	}
+
+	for i, row := range trace {
+		table := min(i, tableSize-1)
+
+		multiplicity := new(math.PrimeField).SetZero()
+		if i < tableSize {
+			multiplicity = math.NewPrimeFieldUint64(multiplicities[i])
+		}
+
+		row.Coefficients = append(row.Coefficients, limbs[i]...)
+		row.Coefficients = append(row.Coefficients, math.NewPrimeField(int64(table)), multiplicity)
+	}
+
+	return trace
+}
+
+// LookupRangeCheckedAIR returns the public statement of the receipt with prices range-checked by lookups
+func (r *Receipt) LookupRangeCheckedAIR() *LookupRangeReceiptAIR {
+	return &LookupRangeReceiptAIR{ReceiptAIR: r.AIR()}
+}
+
+// LookupRangeReceiptAIR is the receipt statement that proves every price to be below 2^32 like RangeCheckedReceiptAIR,
+// but with lookups of 8-bit limbs into a table column instead of 32 bit columns per price.
+// The trace is 8 columns wide, plus 6 auxiliary columns, and at least LookupRangeSteps long.
+type LookupRangeReceiptAIR struct {
+	*ReceiptAIR
+}
+
+func (a *LookupRangeReceiptAIR) TraceWidth() int {
+	return lookupColumns
+}
+
+func (a *LookupRangeReceiptAIR) TransitionDegree() int {
+	return 2
+}
+
+func (a *LookupRangeReceiptAIR) NumTransitionConstraints() int {
+	return a.ReceiptAIR.NumTransitionConstraints() + 2
+}
+
+// EvaluateTransition evaluates the receipt constraints, recomposes the price from its limbs
+// and lets the table either stay or advance by one: (t' - t)·(t' - t - 1) = 0
+func (a *LookupRangeReceiptAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	price := new(math.PrimeField).SetZero()
+	for i := lookupLimbs - 1; i >= 0; i-- {
+		price = new(math.PrimeField).Add(new(math.PrimeField).Mul(price, math.NewPrimeField(1<<LookupLimbBits)), current[lookupLimb+i])
+	}
+
+	step := new(math.PrimeField).Sub(next[lookupTable], current[lookupTable])
+
+	return append(a.ReceiptAIR.EvaluateTransition(current, next),
+		new(math.PrimeField).Sub(current[0], price),
+		new(math.PrimeField).Mul(step, new(math.PrimeField).Sub(step, math.NewPrimeField(1))),
+	)
+}
+
+// Assertions bound the table to 0 ≤ t ≤ 2^LookupLimbBits - 1
+func (a *LookupRangeReceiptAIR) Assertions() []Assertion {
+	return append(a.ReceiptAIR.Assertions(),
+		Assertion{Column: lookupTable, Step: 0, Value: new(math.PrimeField).SetZero()},
+		Assertion{Column: lookupTable, Step: a.Steps - 1, Value: math.NewPrimeField(1<<LookupLimbBits - 1)},
+	)
+}
+
+// AuxiliarySegments returns the lookup of the limbs into the limb table
+func (a *LookupRangeReceiptAIR) AuxiliarySegments() []AuxiliarySegment {
+	return []AuxiliarySegment{lookupRange(a.Steps)}
+}
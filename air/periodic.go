@@ -0,0 +1,114 @@
+package air
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// PeriodicColumn is a public column whose values repeat every len(column) rows.
+// The period must be a power of two that divides the trace length.
+//
+// On a trace domain <ω> of size n the column is P(x) = q(x^(n/k)) for the polynomial q of degree below the period k
+// interpolating the values over the k-th roots of unity, since (ω^i)^(n/k) is the k-th root of unity of index i.
+// The prover interpolates k values instead of n and the verifier evaluates q of degree below k at any point.
+type PeriodicColumn []*math.PrimeField
+
+// Selector returns the periodic column of the period that is 1 on the given steps of every period and 0 elsewhere
+func Selector(period int, steps ...int) PeriodicColumn {
+	column := make(PeriodicColumn, period)
+	for i := range column {
+		column[i] = new(math.PrimeField).SetZero()
+	}
+
+	for _, step := range steps {
+		column[step] = new(math.PrimeField).SetOne()
+	}
+
+	return column
+}
+
+// Period returns the number of rows after which the column repeats
+func (c PeriodicColumn) Period() int {
+	return len(c)
+}
+
+// At returns the value of the column at a step of the trace
+func (c PeriodicColumn) At(step int) *math.PrimeField {
+	return c[step%len(c)]
+}
+
+// Polynomial returns q, the polynomial interpolating the values over the roots of unity of order Period
+func (c PeriodicColumn) Polynomial() *math.Polynom {
+	if len(c) == 1 {
+		return math.NewPolynom([]*math.PrimeField{c[0].Copy()})
+	}
+
+	root := new(math.PrimeField).GetRootOfUnity(uint64(len(c)))
+	return new(math.PrimeField).INTT(root, math.NewPolynom(c))
+}
+
+// EvaluateAt returns P(x) = q(x^(n/k)) from q returned by Polynomial for a trace of the given length
+func (c PeriodicColumn) EvaluateAt(q *math.Polynom, x *math.PrimeField, traceLength int) *math.PrimeField {
+	return q.EvalAt(new(math.PrimeField).Exp(x, big.NewInt(int64(traceLength/len(c)))))
+}
+
+// CheckPeriodic checks that the period of every column is a power of two dividing the trace length
+func CheckPeriodic(columns []PeriodicColumn, traceLength int) error {
+	for i, column := range columns {
+		k := column.Period()
+		if k < 1 || k&(k-1) != 0 || traceLength%k != 0 {
+			return fmt.Errorf("periodic column %d has period %d, expected a power of two dividing %d", i, k, traceLength)
+		}
+	}
+
+	return nil
+}
+
+// PeriodicAIR is an AIR whose transition constraints read public periodic columns, such as round constants
+// or selectors that enable a constraint on some rows of every period only.
+//
+// EvaluateTransition receives the values of the periodic columns after the TraceWidth trace columns
+// of both rows, so column TraceWidth() + i holds periodic column i. TransitionDegree counts periodic columns
+// like trace columns, e.g. a degree 2 constraint gated by a selector has degree 3.
+type PeriodicAIR interface {
+	AIR
+
+	// PeriodicColumns returns the periodic columns read by the transition constraints
+	PeriodicColumns() []PeriodicColumn
+}
+
+// Periodic returns the periodic columns of the AIR, nil if it has none
+func Periodic(a AIR) []PeriodicColumn {
+	if p, ok := a.(PeriodicAIR); ok {
+		return p.PeriodicColumns()
+	}
+
+	return nil
+}
+
+// WithPeriodic returns the row of a step followed by the values of the periodic columns at the step
+func WithPeriodic(row []*math.PrimeField, columns []PeriodicColumn, step int) []*math.PrimeField {
+	if len(columns) == 0 {
+		return row
+	}
+
+	extended := make([]*math.PrimeField, 0, len(row)+len(columns))
+	extended = append(extended, row...)
+	for _, column := range columns {
+		extended = append(extended, column.At(step))
+	}
+
+	return extended
+}
+
+// Gate multiplies every constraint by the selector value, so that the constraints only apply where it is 1
+func Gate(selector *math.PrimeField, constraints []*math.PrimeField) []*math.PrimeField {
+	gated := make([]*math.PrimeField, len(constraints))
+	for i, value := range constraints {
+		gated[i] = new(math.PrimeField).Mul(selector, value)
+	}
+
+	return gated
+}
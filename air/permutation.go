@@ -0,0 +1,226 @@
+package air
+
+import (
+	"slices"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// PermutationChallenges is the number of challenges of a PermutationCheck
+const PermutationChallenges = 2
+
+// PermutationCheck is a grand-product argument that the rows of the Left columns and the rows of the Right
+// columns of the main trace over every step except the last one are equal as multisets.
+// It is an auxiliary segment of a single column for a trace of the given number of Steps.
+//
+// With challenges α and β the tuple of a row is compressed into a = Σ α^i·a_i and the auxiliary column
+// accumulates Z_0 = 1, Z_{k+1} = Z_k·(β - a_k) / (β - b_k), so that Z_{n-1} = Π (β - a_k) / (β - b_k).
+// The product is 1 for a permutation and, by the Schwartz-Zippel lemma, for anything else
+// with probability at most about 2n / |F|, i.e. 2^-43 for a million rows over Goldilocks.
+type PermutationCheck struct {
+	Left  []int
+	Right []int
+	Steps int
+}
+
+// compress returns β - Σ α^i·row[columns[i]] for challenges (α, β)
+func compress(row []*math.PrimeField, columns []int, challenges []*math.PrimeField) *math.PrimeField {
+	alpha, beta := challenges[0], challenges[1]
+
+	value := new(math.PrimeField).SetZero()
+	for i := len(columns) - 1; i >= 0; i-- {
+		value = new(math.PrimeField).Add(new(math.PrimeField).Mul(value, alpha), row[columns[i]])
+	}
+
+	return new(math.PrimeField).Sub(beta, value)
+}
+
+func (c PermutationCheck) Width() int {
+	return 1
+}
+
+func (c PermutationCheck) NumChallenges() int {
+	return PermutationChallenges
+}
+
+// Build returns the running product column of the main trace
+func (c PermutationCheck) Build(segments []ExecutionTrace, challenges []*math.PrimeField) ExecutionTrace {
+	trace := segments[0]
+
+	denominators := make([]*math.PrimeField, len(trace)-1)
+	for k := range denominators {
+		denominators[k] = compress(trace[k].Coefficients, c.Right, challenges)
+	}
+
+	inverses := new(math.PrimeField).MultiInv(denominators)
+
+	column := make([]*math.PrimeField, len(trace))
+	column[0] = new(math.PrimeField).SetOne()
+
+	for k := range denominators {
+		numerator := compress(trace[k].Coefficients, c.Left, challenges)
+		column[k+1] = new(math.PrimeField).Mul(column[k], new(math.PrimeField).Mul(numerator, inverses[k]))
+	}
+
+	return columnTrace(column)
+}
+
+func (c PermutationCheck) NumConstraints() int {
+	return 1
+}
+
+// Evaluate evaluates the degree 2 running product constraint Z'·(β - b) - Z·(β - a)
+func (c PermutationCheck) Evaluate(current, next [][]*math.PrimeField, challenges []*math.PrimeField) []*math.PrimeField {
+	main, z, zNext := current[0], current[len(current)-1][0], next[len(next)-1][0]
+
+	return []*math.PrimeField{
+		new(math.PrimeField).Sub(
+			new(math.PrimeField).Mul(zNext, compress(main, c.Right, challenges)),
+			new(math.PrimeField).Mul(z, compress(main, c.Left, challenges)),
+		),
+	}
+}
+
+// Assertions require the running product to start and end with 1
+func (c PermutationCheck) Assertions(_ []*math.PrimeField) []Assertion {
+	return []Assertion{
+		{Column: 0, Step: 0, Value: new(math.PrimeField).SetOne()},
+		{Column: 0, Step: c.Steps - 1, Value: new(math.PrimeField).SetOne()},
+	}
+}
+
+// columnTrace returns the trace with the given columns
+func columnTrace(columns ...[]*math.PrimeField) ExecutionTrace {
+	trace := make(ExecutionTrace, len(columns[0]))
+	for i := range trace {
+		row := make([]*math.PrimeField, len(columns))
+		for j, column := range columns {
+			row[j] = column[i]
+		}
+		trace[i] = math.NewPolynom(row)
+	}
+
+	return trace
+}
+
+// Columns of the sorted items trace
+const (
+	SortedScannedQuantity = iota
+	SortedScannedPrice
+	SortedQuantity
+	SortedPrice
+
+	// sortedGap holds the difference between the next and the current sorted price
+	sortedGap
+
+	sortedColumns
+)
+
+var (
+	sortedLeft  = []int{SortedScannedQuantity, SortedScannedPrice}
+	sortedRight = []int{SortedQuantity, SortedPrice}
+
+	sortedChecks = []RangeCheck{
+		{Column: SortedScannedPrice, Offset: sortedColumns, Bits: RangeBits},
+		{Column: sortedGap, Offset: sortedColumns + RangeBits, Bits: RangeBits},
+	}
+)
+
+// SortedItems is the trace proving that the items sorted by unit price are a permutation of the scanned items.
+// Row i holds the scanned line i and the sorted line i, the last row repeats the last sorted line.
+type SortedItems struct {
+	Scanned []LineItem
+	Sorted  []LineItem
+}
+
+// ComputeSortedItems pads the scanned items with empty lines, so that the trace length is a power of two,
+// and sorts them by unit price
+func ComputeSortedItems(items []LineItem) *SortedItems {
+	scanned := append([]LineItem{}, items...)
+	for len(scanned) < 1 || (len(scanned)+1)&len(scanned) != 0 {
+		scanned = append(scanned, NewLineItem(0, 0))
+	}
+
+	sorted := slices.Clone(scanned)
+	slices.SortStableFunc(sorted, func(a, b LineItem) int {
+		return a.UnitPrice.Cmp(b.UnitPrice)
+	})
+
+	return &SortedItems{Scanned: scanned, Sorted: sorted}
+}
+
+// Trace returns the rows (scanned quantity, scanned price, sorted quantity, sorted price, gap)
+// followed by the binary decompositions of the scanned price and the gap
+func (s *SortedItems) Trace() ExecutionTrace {
+	trace := make(ExecutionTrace, len(s.Scanned)+1)
+
+	for i := range trace {
+		scanned, sorted := NewLineItem(0, 0), s.Sorted[min(i, len(s.Sorted)-1)]
+		if i < len(s.Scanned) {
+			scanned = s.Scanned[i]
+		}
+
+		gap := new(math.PrimeField).SetZero()
+		if i+1 < len(s.Sorted) {
+			gap = new(math.PrimeField).Sub(s.Sorted[i+1].UnitPrice, sorted.UnitPrice)
+		}
+
+		row := []*math.PrimeField{scanned.Quantity, scanned.UnitPrice, sorted.Quantity, sorted.UnitPrice, gap}
+		for _, check := range sortedChecks {
+			row = append(row, check.Decompose(row[check.Column])...)
+		}
+
+		trace[i] = math.NewPolynom(row)
+	}
+
+	return trace
+}
+
+// AIR returns the statement of the sorted items
+func (s *SortedItems) AIR() *SortedItemsAIR {
+	return &SortedItemsAIR{Steps: len(s.Scanned) + 1}
+}
+
+// SortedItemsAIR is the statement that the sorted lines are the scanned lines in non-decreasing order of unit price.
+// The scanned prices and the gaps between sorted prices are below 2^32, so the sorted prices cannot wrap around the modulus.
+type SortedItemsAIR struct {
+	Steps int
+}
+
+func (a *SortedItemsAIR) TraceLength() int {
+	return a.Steps
+}
+
+func (a *SortedItemsAIR) TraceWidth() int {
+	return sortedColumns + len(sortedChecks)*RangeBits
+}
+
+func (a *SortedItemsAIR) TransitionDegree() int {
+	return 2
+}
+
+func (a *SortedItemsAIR) NumTransitionConstraints() int {
+	return 1 + len(sortedChecks)*(RangeBits+1)
+}
+
+// EvaluateTransition checks that the gap is the difference of consecutive sorted prices and that it is below 2^32
+func (a *SortedItemsAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	res := []*math.PrimeField{
+		new(math.PrimeField).Sub(current[sortedGap], new(math.PrimeField).Sub(next[SortedPrice], current[SortedPrice])),
+	}
+
+	for _, check := range sortedChecks {
+		res = append(res, check.Evaluate(current)...)
+	}
+
+	return res
+}
+
+func (a *SortedItemsAIR) Assertions() []Assertion {
+	return nil
+}
+
+// AuxiliarySegments returns the permutation argument between scanned and sorted lines
+func (a *SortedItemsAIR) AuxiliarySegments() []AuxiliarySegment {
+	return []AuxiliarySegment{PermutationCheck{Left: sortedLeft, Right: sortedRight, Steps: a.Steps}}
+}
@@ -0,0 +1,112 @@
+package air
+
+import (
+	"github.com/KyrylR/simple-air/math"
+)
+
+// RangeBits is the bit size of range-checked amounts: prices, quantities and line totals
+const RangeBits = 32
+
+// RangeCheck constrains a trace column to values below 2^Bits with a binary decomposition
+// of the value stored in Bits consecutive columns starting at Offset, least significant bit first.
+//
+// A sum of k values below 2^32 is below k·2^32, so it does not wrap around the Goldilocks modulus
+// 2^64 - 2^32 + 1 for any k ≤ 2^32 - 1, which covers every trace length the prover supports.
+// Likewise a product of two values below 2^32 is at most 2^64 - 2^33 + 1 and never wraps.
+type RangeCheck struct {
+	Column int
+	Offset int
+	Bits   int
+}
+
+// Decompose returns the Bits low bits of the value, values that do not fit are truncated
+// and then fail the recomposition constraint
+func (c RangeCheck) Decompose(value *math.PrimeField) []*math.PrimeField {
+	v := value.Uint64()
+
+	bits := make([]*math.PrimeField, c.Bits)
+	for i := range bits {
+		bits[i] = math.NewPrimeFieldUint64((v >> i) & 1)
+	}
+
+	return bits
+}
+
+// NumConstraints returns the number of values returned by Evaluate
+func (c RangeCheck) NumConstraints() int {
+	return c.Bits + 1
+}
+
+// Evaluate evaluates the degree 2 constraints of the check on a row:
+// every bit is either zero or one, b·(b - 1) = 0, and the bits recompose the value
+func (c RangeCheck) Evaluate(row []*math.PrimeField) []*math.PrimeField {
+	res := make([]*math.PrimeField, 0, c.NumConstraints())
+
+	sum := new(math.PrimeField).SetZero()
+	power := new(math.PrimeField).SetOne()
+	one := math.NewPrimeField(1)
+	two := math.NewPrimeField(2)
+
+	for i := range c.Bits {
+		bit := row[c.Offset+i]
+
+		res = append(res, new(math.PrimeField).Mul(bit, new(math.PrimeField).Sub(bit, one)))
+
+		sum.Add(sum, new(math.PrimeField).Mul(bit, power))
+		power.Mul(power, two)
+	}
+
+	return append(res, new(math.PrimeField).Sub(row[c.Column], sum))
+}
+
+// priceCheck range checks the prices of a receipt trace
+var priceCheck = RangeCheck{Column: 0, Offset: 2, Bits: RangeBits}
+
+// RangeCheckedTrace returns the receipt trace extended with the binary decomposition of every price.
+// The last row holds the total in place of a price, its bits are left zero.
+func (r *Receipt) RangeCheckedTrace() ExecutionTrace {
+	trace := r.Trace()
+
+	for i, row := range trace {
+		bits := make([]*math.PrimeField, priceCheck.Bits)
+		if i < len(trace)-1 {
+			bits = priceCheck.Decompose(r.First[i])
+		} else {
+			for j := range bits {
+				bits[j] = new(math.PrimeField).SetZero()
+			}
+		}
+
+		row.Coefficients = append(row.Coefficients, bits...)
+	}
+
+	return trace
+}
+
+// RangeCheckedAIR returns the public statement of the receipt with range-checked prices
+func (r *Receipt) RangeCheckedAIR() *RangeCheckedReceiptAIR {
+	return &RangeCheckedReceiptAIR{ReceiptAIR: r.AIR()}
+}
+
+// RangeCheckedReceiptAIR is the receipt statement that additionally proves every price to be below 2^32,
+// which rules out "negative" prices p - x and guarantees that the total does not wrap around the modulus
+type RangeCheckedReceiptAIR struct {
+	*ReceiptAIR
+}
+
+func (a *RangeCheckedReceiptAIR) TraceWidth() int {
+	return 2 + priceCheck.Bits
+}
+
+func (a *RangeCheckedReceiptAIR) TransitionDegree() int {
+	return 2
+}
+
+func (a *RangeCheckedReceiptAIR) NumTransitionConstraints() int {
+	return a.ReceiptAIR.NumTransitionConstraints() + priceCheck.NumConstraints()
+}
+
+// EvaluateTransition evaluates the receipt constraints and the range check of the current price
+func (a *RangeCheckedReceiptAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	return append(a.ReceiptAIR.EvaluateTransition(current, next), priceCheck.Evaluate(current)...)
+}
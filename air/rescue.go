@@ -0,0 +1,164 @@
+package air
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+)
+
+// RescuePreimage is the execution of the Rescue hash over a preimage of at most rescue.Rate elements.
+// The trace has one row per permutation state: the initial state and the state after every round.
+type RescuePreimage struct {
+	Length int
+	States [][]*math.PrimeField
+}
+
+// ComputeRescue hashes the preimage and records every intermediate state
+func ComputeRescue(preimage []*math.PrimeField) (*RescuePreimage, error) {
+	if len(preimage) > rescue.Rate {
+		return nil, fmt.Errorf("preimage must have at most %d elements, got %d", rescue.Rate, len(preimage))
+	}
+
+	state := rescue.InitialState(len(preimage))
+	for i, x := range preimage {
+		state[i] = x.Copy()
+	}
+
+	states := make([][]*math.PrimeField, rescue.Rounds+1)
+	states[0] = state
+
+	for r := range rescue.Rounds {
+		states[r+1] = rescue.Round(states[r], r)
+	}
+
+	return &RescuePreimage{
+		Length: len(preimage),
+		States: states,
+	}, nil
+}
+
+// Digest returns the hash of the preimage
+func (r *RescuePreimage) Digest() []*math.PrimeField {
+	return r.States[rescue.Rounds][:rescue.DigestElements]
+}
+
+// Trace returns the permutation states as rows
+func (r *RescuePreimage) Trace() ExecutionTrace {
+	trace := make(ExecutionTrace, len(r.States))
+	for i, state := range r.States {
+		trace[i] = math.NewPolynom(state)
+	}
+
+	return trace
+}
+
+// BoundaryConstraints returns the public statement "the trace hashes some preimage of length to digest"
+func (r *RescuePreimage) BoundaryConstraints() []Assertion {
+	return RescueAssertions(r.Length, r.Digest())
+}
+
+// RescueAssertions returns the boundary constraints of a preimage of the given length hashing to digest:
+// the rate columns after the preimage and the capacity of the first row hold the initial sponge state
+// and the last row starts with the digest. The first length columns, i.e. the preimage itself, are left unconstrained.
+func RescueAssertions(length int, digest []*math.PrimeField) []Assertion {
+	initial := rescue.InitialState(length)
+
+	assertions := make([]Assertion, 0, rescue.Width-length+len(digest))
+	for i := max(length, 0); i < rescue.Width; i++ {
+		assertions = append(assertions, Assertion{Column: i, Step: 0, Value: initial[i]})
+	}
+
+	for i, d := range digest {
+		assertions = append(assertions, Assertion{Column: i, Step: rescue.Rounds, Value: d})
+	}
+
+	return assertions
+}
+
+// RescueTransitionConstraints evaluates the constraints of round step between two consecutive states.
+//
+// A round computes next = MDS·(MDS·current^α + C1)^(1/α) + C2, which is equivalent to the degree α relation
+// MDS·current^α + C1 = (MDS^-1·(next - C2))^α that does not need the inverse S-box.
+//
+// The step must be below rescue.Rounds. A step outside the permutation has no round constants,
+// it evaluates to the single nonzero constraint 1, so that a trace longer than rescue.Rounds+1 rows is rejected.
+func RescueTransitionConstraints(step int, current, next []*math.PrimeField) []*math.PrimeField {
+	if step < 0 || step >= rescue.Rounds {
+		return []*math.PrimeField{math.NewPrimeField(1)}
+	}
+
+	return rescueRound(rescue.RoundConstants[step], current, next)
+}
+
+// rescueRound evaluates the round constraints with the 2·rescue.Width round constants of the round
+func rescueRound(constants, current, next []*math.PrimeField) []*math.PrimeField {
+	forward := rescue.AddConstants(rescue.MulMatrix(rescue.MDS, rescue.PowAlpha(current)), constants[:rescue.Width])
+
+	negated := make([]*math.PrimeField, rescue.Width)
+	for i, c := range constants[rescue.Width:] {
+		negated[i] = new(math.PrimeField).Neg(c)
+	}
+	backward := rescue.PowAlpha(rescue.MulMatrix(rescue.MDSInv, rescue.AddConstants(next, negated)))
+
+	output := make([]*math.PrimeField, rescue.Width)
+	for i := range output {
+		output[i] = new(math.PrimeField).Sub(forward[i], backward[i])
+	}
+
+	return output
+}
+
+// RescueCheck constrains rescue.Rounds+1 groups of rescue.Width consecutive columns starting at Offset
+// to hold the states of a single Rescue permutation: the input state and the state after every round.
+// Unlike RescuePreimage, which spends a row per round, the whole permutation fits in one row,
+// so the round constants are fixed per column and the constraints do not depend on the step.
+type RescueCheck struct {
+	Offset int
+}
+
+// RescueCheckColumns is the number of columns used by a RescueCheck
+const RescueCheckColumns = (rescue.Rounds + 1) * rescue.Width
+
+// States returns the input state followed by the state after every round, flattened into columns
+func (c RescueCheck) States(input []*math.PrimeField) []*math.PrimeField {
+	states := make([]*math.PrimeField, 0, RescueCheckColumns)
+	states = append(states, input...)
+
+	state := input
+	for r := range rescue.Rounds {
+		state = rescue.Round(state, r)
+		states = append(states, state...)
+	}
+
+	return states
+}
+
+// Input returns the input state of the permutation in a row
+func (c RescueCheck) Input(row []*math.PrimeField) []*math.PrimeField {
+	return row[c.Offset : c.Offset+rescue.Width]
+}
+
+// Output returns the output state of the permutation in a row
+func (c RescueCheck) Output(row []*math.PrimeField) []*math.PrimeField {
+	return row[c.Offset+RescueCheckColumns-rescue.Width : c.Offset+RescueCheckColumns]
+}
+
+// NumConstraints returns the number of values returned by Evaluate
+func (c RescueCheck) NumConstraints() int {
+	return rescue.Rounds * rescue.Width
+}
+
+// Evaluate evaluates the degree rescue.Alpha round constraints between consecutive states of a row
+func (c RescueCheck) Evaluate(row []*math.PrimeField) []*math.PrimeField {
+	res := make([]*math.PrimeField, 0, c.NumConstraints())
+
+	for r := range rescue.Rounds {
+		current := row[c.Offset+r*rescue.Width : c.Offset+(r+1)*rescue.Width]
+		next := row[c.Offset+(r+1)*rescue.Width : c.Offset+(r+2)*rescue.Width]
+
+		res = append(res, RescueTransitionConstraints(r, current, next)...)
+	}
+
+	return res
+}
@@ -85,3 +85,60 @@ func (r *Receipt) TransitionalConstraints(omicron *math.PrimeField) *math.Polyno
 
 	return C
 }
+
+// ComputePadded computes the receipt of prices padded with zero prices,
+// so that the trace length is a power of two
+func ComputePadded(prices []*math.PrimeField) *Receipt {
+	padded := append([]*math.PrimeField{}, prices...)
+	for len(padded) < 1 || (len(padded)+1)&len(padded) != 0 {
+		padded = append(padded, new(math.PrimeField).SetZero())
+	}
+
+	return Compute(padded)
+}
+
+// ReceiptAIR is the public statement of a receipt: its trace length and total
+type ReceiptAIR struct {
+	Steps int
+	Total *math.PrimeField
+}
+
+// AIR returns the public statement of the receipt
+func (r *Receipt) AIR() *ReceiptAIR {
+	return &ReceiptAIR{
+		Steps: len(r.First),
+		Total: r.Second[len(r.Second)-1],
+	}
+}
+
+func (a *ReceiptAIR) TraceLength() int {
+	return a.Steps
+}
+
+func (a *ReceiptAIR) TraceWidth() int {
+	return 2
+}
+
+func (a *ReceiptAIR) TransitionDegree() int {
+	return 1
+}
+
+func (a *ReceiptAIR) NumTransitionConstraints() int {
+	return 1
+}
+
+// EvaluateTransition checks that the running sum is updated with the price: S' = F + S
+func (a *ReceiptAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	return []*math.PrimeField{
+		new(math.PrimeField).Sub(next[1], new(math.PrimeField).Add(current[0], current[1])),
+	}
+}
+
+// Assertions require the running sum to start at zero and both columns to end with the total
+func (a *ReceiptAIR) Assertions() []Assertion {
+	return []Assertion{
+		{Column: 1, Step: 0, Value: new(math.PrimeField).SetZero()},
+		{Column: 0, Step: a.Steps - 1, Value: a.Total},
+		{Column: 1, Step: a.Steps - 1, Value: a.Total},
+	}
+}
@@ -0,0 +1,213 @@
+package air
+
+import (
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+)
+
+// rescueCycle is the number of rows per permutation of a RescueHash: the input state and the state after every round
+const rescueCycle = rescue.Rounds + 1
+
+// Columns of the Rescue hash trace: the sponge state and the block absorbed after the permutation,
+// which is only set on the last row of every permutation
+const (
+	rescueState = 0
+	rescueBlock = rescueState + rescue.Width
+
+	rescueHashColumns = rescueBlock + rescue.Rate
+)
+
+// Periodic columns of the Rescue hash AIR: the round selector followed by the round constants
+const (
+	rescueRoundSelector = 0
+	rescueConstants     = 1
+)
+
+// RescueHash is the execution of the Rescue hash over an input of any length with one round per row,
+// unlike RescuePreimage it is an AIR: the round constants are periodic columns instead of depending on the step
+type RescueHash struct {
+	Input []*math.PrimeField
+
+	// States holds the sponge state in every row of the trace
+	States [][]*math.PrimeField
+}
+
+// rescueBlocks returns the number of permutations absorbing an input of the given length
+func rescueBlocks(length int) int {
+	return max((length+rescue.Rate-1)/rescue.Rate, 1)
+}
+
+// block returns the zero-padded block j of the input
+func (h *RescueHash) block(j int) []*math.PrimeField {
+	block := make([]*math.PrimeField, rescue.Rate)
+	for i := range block {
+		if k := j*rescue.Rate + i; k < len(h.Input) {
+			block[i] = h.Input[k]
+		} else {
+			block[i] = new(math.PrimeField).SetZero()
+		}
+	}
+
+	return block
+}
+
+// ComputeRescueHash hashes the input and records every round. The trace is padded to a power of two
+// with permutations absorbing zero blocks after the digest.
+func ComputeRescueHash(input []*math.PrimeField) *RescueHash {
+	h := &RescueHash{Input: input}
+
+	permutations := 1
+	for permutations < rescueBlocks(len(input)) {
+		permutations *= 2
+	}
+
+	state := rescue.InitialState(len(input))
+	for j := range permutations {
+		state = append(rescue.AddConstants(state[:rescue.Rate], h.block(j)), state[rescue.Rate:]...)
+		h.States = append(h.States, state)
+
+		for r := range rescue.Rounds {
+			state = rescue.Round(state, r)
+			h.States = append(h.States, state)
+		}
+	}
+
+	return h
+}
+
+// Digest returns the hash of the input
+func (h *RescueHash) Digest() []*math.PrimeField {
+	return h.States[rescueBlocks(len(h.Input))*rescueCycle-1][:rescue.DigestElements]
+}
+
+// Trace returns the sponge state of every row followed by the next input block on the last row of every permutation
+func (h *RescueHash) Trace() ExecutionTrace {
+	trace := make(ExecutionTrace, len(h.States))
+
+	for i, state := range h.States {
+		block := make([]*math.PrimeField, rescue.Rate)
+		for k := range block {
+			block[k] = new(math.PrimeField).SetZero()
+		}
+
+		if i%rescueCycle == rescueCycle-1 {
+			block = h.block(i/rescueCycle + 1)
+		}
+
+		trace[i] = math.NewPolynom(append(append([]*math.PrimeField{}, state...), block...))
+	}
+
+	return trace
+}
+
+// AIR returns the public statement of the hash: the input length and the digest
+func (h *RescueHash) AIR() *RescueHashAIR {
+	return &RescueHashAIR{Length: len(h.Input), Digest: h.Digest()}
+}
+
+// RescueHashAIR is the statement "some input of Length elements hashes to Digest with rescue.Hash".
+//
+// Every permutation spans rescueCycle rows, the periodic round selector enables the round constraints
+// on the first rescue.Rounds rows of the cycle and the absorption constraints on the last one.
+// The padding of the last block is asserted to be zero, so the input is unique for the digest.
+type RescueHashAIR struct {
+	Length int
+	Digest []*math.PrimeField
+}
+
+func (a *RescueHashAIR) TraceLength() int {
+	permutations := 1
+	for permutations < rescueBlocks(a.Length) {
+		permutations *= 2
+	}
+
+	return permutations * rescueCycle
+}
+
+func (a *RescueHashAIR) TraceWidth() int {
+	return rescueHashColumns
+}
+
+// TransitionDegree is the degree rescue.Alpha of the S-box times the round selector
+func (a *RescueHashAIR) TransitionDegree() int {
+	return rescue.Alpha + 1
+}
+
+func (a *RescueHashAIR) NumTransitionConstraints() int {
+	return 2 * rescue.Width
+}
+
+// PeriodicColumns returns the round selector and the 2·rescue.Width round constants, which are zero on the absorbing row
+func (a *RescueHashAIR) PeriodicColumns() []PeriodicColumn {
+	rounds := make([]int, rescue.Rounds)
+	for r := range rounds {
+		rounds[r] = r
+	}
+
+	columns := []PeriodicColumn{Selector(rescueCycle, rounds...)}
+	for i := range 2 * rescue.Width {
+		column := make(PeriodicColumn, rescueCycle)
+		for r := range column {
+			if r < rescue.Rounds {
+				column[r] = rescue.RoundConstants[r][i]
+			} else {
+				column[r] = new(math.PrimeField).SetZero()
+			}
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// EvaluateTransition applies a round on round rows, and on absorbing rows adds the block to the rate
+// and keeps the capacity
+func (a *RescueHashAIR) EvaluateTransition(current, next []*math.PrimeField) []*math.PrimeField {
+	periodic := current[rescueHashColumns:]
+	round := periodic[rescueRoundSelector]
+	absorb := new(math.PrimeField).Sub(math.NewPrimeField(1), round)
+
+	state, nextState := current[rescueState:rescueBlock], next[rescueState:rescueBlock]
+	constants := periodic[rescueConstants : rescueConstants+2*rescue.Width]
+
+	res := Gate(round, rescueRound(constants, state, nextState))
+
+	absorbed := make([]*math.PrimeField, rescue.Width)
+	for i := range absorbed {
+		absorbed[i] = new(math.PrimeField).Sub(nextState[i], state[i])
+		if i < rescue.Rate {
+			absorbed[i] = new(math.PrimeField).Sub(absorbed[i], current[rescueBlock+i])
+		}
+	}
+
+	return append(res, Gate(absorb, absorbed)...)
+}
+
+// Assertions fix the initial capacity, the zero padding of the last block and the digest after the last block
+func (a *RescueHashAIR) Assertions() []Assertion {
+	blocks := rescueBlocks(a.Length)
+	initial := rescue.InitialState(a.Length)
+
+	assertions := make([]Assertion, 0, rescue.Width+rescue.DigestElements)
+	for i := rescue.Rate; i < rescue.Width; i++ {
+		assertions = append(assertions, Assertion{Column: rescueState + i, Step: 0, Value: initial[i]})
+	}
+
+	// The last block is the rate of the first row if there is a single block, and the block columns
+	// of the row before the last permutation otherwise
+	column, step := rescueState, 0
+	if blocks > 1 {
+		column, step = rescueBlock, (blocks-1)*rescueCycle-1
+	}
+
+	for i := a.Length - (blocks-1)*rescue.Rate; i < rescue.Rate; i++ {
+		assertions = append(assertions, Assertion{Column: column + i, Step: step, Value: new(math.PrimeField).SetZero()})
+	}
+
+	for i, d := range a.Digest {
+		assertions = append(assertions, Assertion{Column: rescueState + i, Step: blocks*rescueCycle - 1, Value: d})
+	}
+
+	return assertions
+}
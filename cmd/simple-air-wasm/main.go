@@ -0,0 +1,26 @@
+//go:build js && wasm
+
+// Command simple-air-wasm is the WebAssembly receipt verifier for browsers. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o verifier.wasm ./cmd/simple-air-wasm
+//
+// and load it with wasm_exec.js of the Go distribution, or build it with make wasm-tinygo and load it with
+// wasm_exec.js of the TinyGo distribution. Once started it defines the global function
+//
+//	verify(proofBytes: Uint8Array, publicInputsJSON: string): {valid: boolean, error?: string}
+//
+// where the public inputs are {"total": "<decimal>", "steps": <trace length, optional>}.
+// Proofs are verified with stark.DefaultParameters, like the defaults of the simple-air command.
+package main
+
+import (
+	"github.com/KyrylR/simple-air/stark"
+	"github.com/KyrylR/simple-air/wasm"
+)
+
+func main() {
+	wasm.Register(stark.DefaultParameters())
+
+	// Keep the Go runtime alive for the calls from JavaScript
+	select {}
+}
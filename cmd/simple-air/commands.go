@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/ingest"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/server"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// errUsage is wrapped by the errors of commands called with wrong flags or arguments
+var errUsage = errors.New("usage error")
+
+// newFlagSet returns the flag set of a command, which prints its help to stderr
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: simple-air %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses the flags of a command, reports a usage error for unexpected arguments
+// and for required flags left empty
+func parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("%w: flag -%s is required", errUsage, name)
+		}
+	}
+
+	return nil
+}
+
+// parameterFlags registers the proof parameter flags and returns a function building the parameters from them
+func parameterFlags(fs *flag.FlagSet) func() (stark.Parameters, error) {
+	defaults := stark.DefaultParameters()
+
+	blowup := fs.Uint("blowup", uint(defaults.BlowupFactor), "blowup factor of the evaluation domain")
+	queries := fs.Uint("queries", uint(defaults.NumQueries), "number of FRI queries")
+	grinding := fs.Uint("grinding", uint(defaults.GrindingBits), "proof-of-work bits")
+	remainder := fs.Uint("remainder", uint(defaults.MaxRemainderDegree), "maximal degree of the FRI remainder")
+	hash := fs.String("hash", defaults.Hash.String(), "hash function: sha256, poseidon2 or keccak256")
+	zk := fs.Bool("zk", defaults.ZeroKnowledge, "make the proof zero-knowledge")
+
+	return func() (stark.Parameters, error) {
+		params := stark.Parameters{
+			BlowupFactor:       uint32(*blowup),
+			NumQueries:         uint32(*queries),
+			GrindingBits:       uint32(*grinding),
+			MaxRemainderDegree: uint32(*remainder),
+			ZeroKnowledge:      *zk,
+		}
+
+		switch *hash {
+		case stark.HashSHA256.String():
+			params.Hash = stark.HashSHA256
+		case stark.HashPoseidon2.String():
+			params.Hash = stark.HashPoseidon2
+		case stark.HashKeccak256.String():
+			params.Hash = stark.HashKeccak256
+		default:
+			return params, fmt.Errorf("%w: unknown hash function %q", errUsage, *hash)
+		}
+
+		if err := params.Validate(); err != nil {
+			return params, fmt.Errorf("%w: %v", errUsage, err)
+		}
+
+		return params, nil
+	}
+}
+
+// readReceipt reads a receipt written by the compute command
+func readReceipt(path string) (*air.Receipt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := new(air.Receipt)
+	if err = json.Unmarshal(data, receipt); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	if len(receipt.First) == 0 {
+		return nil, fmt.Errorf("read %s: receipt is empty", path)
+	}
+
+	return receipt, nil
+}
+
+// readProof reads a proof written by the prove command
+func readProof(path string) (*stark.Proof, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := new(stark.Proof)
+	if err = proof.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return proof, nil
+}
+
+// writeOutput writes data to the file, or to stdout if the path is empty
+func writeOutput(path string, data []byte, stdout io.Writer) error {
+	if path == "" {
+		_, err := stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// parseElement parses a decimal field element given on the command line
+func parseElement(name, value string) (*math.PrimeField, error) {
+	element := new(math.PrimeField)
+	if err := element.UnmarshalText([]byte(value)); err != nil {
+		return nil, fmt.Errorf("%w: %s %q: %v", errUsage, name, value, err)
+	}
+
+	return element, nil
+}
+
+func runCompute(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("compute", "[<price>...]", stderr)
+	out := fs.String("out", "", "write the receipt to `file` instead of stdout")
+	pad := fs.Bool("pad", true, "pad the prices with zeros, so that the trace length is a power of two")
+	csvPath := fs.String("csv", "", "read the lines (sku, qty, price) of the receipt from a CSV `file`")
+	jsonPath := fs.String("json", "", "read the lines of the receipt from a JSON `file`")
+	scale := fs.Int("scale", ingest.DefaultScale, "decimal places of the minor unit of CSV and JSON prices")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	prices, err := computePrices(fs.Args(), *csvPath, *jsonPath, *scale)
+	if err != nil {
+		return err
+	}
+
+	compute := air.Compute
+	if *pad {
+		compute = air.ComputePadded
+	}
+	receipt := compute(prices)
+
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*out, append(data, '\n'), stdout)
+}
+
+// computePrices returns the prices given as arguments, or the line amounts of a CSV or a JSON receipt
+func computePrices(args []string, csvPath, jsonPath string, scale int) ([]*math.PrimeField, error) {
+	read := ingest.ReadCSV
+	path := csvPath
+	if jsonPath != "" {
+		read, path = ingest.ReadJSON, jsonPath
+	}
+
+	switch {
+	case csvPath != "" && jsonPath != "":
+		return nil, fmt.Errorf("%w: -csv and -json are exclusive", errUsage)
+	case path != "" && len(args) != 0:
+		return nil, fmt.Errorf("%w: prices are given both as arguments and as a file", errUsage)
+	case path == "" && len(args) == 0:
+		return nil, fmt.Errorf("%w: no prices", errUsage)
+	}
+
+	if path == "" {
+		prices := make([]*math.PrimeField, len(args))
+		for i, arg := range args {
+			price, err := parseElement("price", arg)
+			if err != nil {
+				return nil, err
+			}
+
+			prices[i] = price
+		}
+
+		return prices, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	receipt, err := read(file, scale)
+	if err != nil {
+		return nil, fmt.Errorf("read %s:\n%w", path, err)
+	}
+
+	return receipt.Prices(), nil
+}
+
+func runTrace(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("trace", "", stderr)
+	path := fs.String("receipt", "", "read the receipt from `file`")
+
+	if err := parse(fs, args, "receipt"); err != nil {
+		return err
+	}
+
+	receipt, err := readReceipt(*path)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "step\tprice\tsum\t")
+	for i, row := range receipt.Trace() {
+		fmt.Fprintf(w, "%d\t%v\t%v\t\n", i, row.At(0), row.At(1))
+	}
+
+	return w.Flush()
+}
+
+func runCheck(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("check", "", stderr)
+	path := fs.String("receipt", "", "read the receipt from `file`")
+
+	if err := parse(fs, args, "receipt"); err != nil {
+		return err
+	}
+
+	receipt, err := readReceipt(*path)
+	if err != nil {
+		return err
+	}
+
+	statement := receipt.AIR()
+	if err = air.Check(statement, receipt.Trace()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "ok: %d steps, total %v\n", statement.Steps, statement.Total)
+
+	return nil
+}
+
+func runProve(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("prove", "", stderr)
+	path := fs.String("receipt", "", "read the receipt from `file`")
+	out := fs.String("out", "", "write the proof to `file`")
+	parameters := parameterFlags(fs)
+
+	if err := parse(fs, args, "receipt", "out"); err != nil {
+		return err
+	}
+
+	params, err := parameters()
+	if err != nil {
+		return err
+	}
+
+	receipt, err := readReceipt(*path)
+	if err != nil {
+		return err
+	}
+
+	statement := receipt.AIR()
+	proof, err := stark.Prove(statement, receipt.Trace(), params)
+	if err != nil {
+		return err
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "proved total %v over %d steps, wrote %d bytes to %s\n", statement.Total, statement.Steps, len(data), *out)
+
+	return nil
+}
+
+func runVerify(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", "", stderr)
+	path := fs.String("proof", "", "read the proof from `file`")
+	total := fs.String("total", "", "the claimed receipt total")
+	steps := fs.Int("steps", 0, "the trace length of the receipt, the one of the proof if 0")
+	parameters := parameterFlags(fs)
+
+	if err := parse(fs, args, "proof", "total"); err != nil {
+		return err
+	}
+
+	params, err := parameters()
+	if err != nil {
+		return err
+	}
+
+	claimed, err := parseElement("total", *total)
+	if err != nil {
+		return err
+	}
+
+	proof, err := readProof(*path)
+	if err != nil {
+		return err
+	}
+
+	statement := &air.ReceiptAIR{Steps: *steps, Total: claimed}
+	if statement.Steps == 0 {
+		statement.Steps = int(proof.TraceLength)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "ok: total %v over %d steps\n", statement.Total, statement.Steps)
+
+	return nil
+}
+
+func runInspect(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("inspect", "", stderr)
+	path := fs.String("proof", "", "read the proof from `file`")
+
+	if err := parse(fs, args, "proof"); err != nil {
+		return err
+	}
+
+	proof, err := readProof(*path)
+	if err != nil {
+		return err
+	}
+
+	params := proof.Parameters
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "version\t%d\n", stark.ProofVersion)
+	fmt.Fprintf(w, "hash\t%v\n", params.Hash)
+	fmt.Fprintf(w, "blowup\t%d\n", params.BlowupFactor)
+	fmt.Fprintf(w, "queries\t%d\n", params.NumQueries)
+	fmt.Fprintf(w, "grinding bits\t%d\n", params.GrindingBits)
+	fmt.Fprintf(w, "max remainder degree\t%d\n", params.MaxRemainderDegree)
+	fmt.Fprintf(w, "zero knowledge\t%t\n", params.ZeroKnowledge)
+	fmt.Fprintf(w, "trace\t%d x %d\n", proof.TraceLength, proof.TraceWidth)
+	fmt.Fprintf(w, "auxiliary segments\t%d\n", len(proof.AuxiliaryRoots))
+	fmt.Fprintf(w, "fri layers\t%d\n", len(proof.FRI.Roots))
+	fmt.Fprintln(w)
+
+	size := 0
+	fmt.Fprintln(w, "section\tbytes")
+	for _, section := range proof.Sizes() {
+		fmt.Fprintf(w, "%s\t%d\n", section.Name, section.Bytes)
+		size += section.Bytes
+	}
+	fmt.Fprintf(w, "total\t%d\n", size)
+
+	return w.Flush()
+}
+
+func runServe(args []string, stdout, stderr io.Writer) error {
+	defaults := server.DefaultConfig()
+
+	fs := newFlagSet("serve", "", stderr)
+	addr := fs.String("addr", "localhost:8080", "listen on `address`")
+	workers := fs.Int("workers", defaults.Workers, "number of jobs proven at the same time")
+	queue := fs.Int("queue", defaults.QueueSize, "number of jobs waiting for a worker")
+	maxBody := fs.Int64("max-body", defaults.MaxBodyBytes, "size limit of request bodies in bytes")
+	maxSteps := fs.Int("max-steps", defaults.MaxSteps, "largest trace length of a receipt")
+	jobTimeout := fs.Duration("job-timeout", defaults.JobTimeout, "time a job may take to prove")
+	parameters := parameterFlags(fs)
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	params, err := parameters()
+	if err != nil {
+		return err
+	}
+
+	config := defaults
+	config.Parameters = params
+	config.Workers, config.QueueSize = *workers, *queue
+	config.MaxBodyBytes, config.MaxSteps = *maxBody, *maxSteps
+	config.JobTimeout = *jobTimeout
+
+	s, err := server.New(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	defer s.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: *addr, Handler: s, ReadHeaderTimeout: config.RequestTimeout}
+
+	errs := make(chan error, 1)
+	go func() { errs <- httpServer.ListenAndServe() }()
+
+	fmt.Fprintf(stdout, "listening on %s\n", *addr)
+
+	select {
+	case err = <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+	defer cancel()
+
+	return httpServer.Shutdown(shutdown)
+}
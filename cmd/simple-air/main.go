@@ -0,0 +1,92 @@
+// Command simple-air computes, proves and verifies receipts from the command line.
+//
+// Usage:
+//
+//	simple-air <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	compute  compute the receipt of prices and print it as JSON
+//	trace    print the execution trace of a receipt as a table
+//	check    check that the trace of a receipt satisfies the receipt AIR
+//	prove    prove a receipt and write the binary proof to a file
+//	verify   verify a proof of a receipt total
+//	inspect  print the parameters, the statement and the section sizes of a proof
+//	serve    serve the HTTP proving service of package server
+//
+// Run "simple-air <command> -h" for the flags of a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{"compute", "compute the receipt of prices and print it as JSON", runCompute},
+	{"trace", "print the execution trace of a receipt as a table", runTrace},
+	{"check", "check that the trace of a receipt satisfies the receipt AIR", runCheck},
+	{"prove", "prove a receipt and write the binary proof to a file", runProve},
+	{"verify", "verify a proof of a receipt total", runVerify},
+	{"inspect", "print the parameters, the statement and the section sizes of a proof", runInspect},
+	{"serve", "serve the HTTP proving service of package server", runServe},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code:
+// 0 on success, 1 if the command failed and 2 on usage errors
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+
+		err := c.run(args[1:], stdout, stderr)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "simple-air %s: %v\n", c.name, err)
+			return 2
+		default:
+			fmt.Fprintf(stderr, "simple-air %s: %v\n", c.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "simple-air: unknown command %q\n", args[0])
+	usage(stderr)
+
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: simple-air <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "simple-air <command> -h" for the flags of a command.`)
+}
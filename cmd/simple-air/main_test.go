@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// execute runs the command line and returns the exit code and the output
+func execute(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestProveVerifyRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	receipt := filepath.Join(dir, "receipt.json")
+	proof := filepath.Join(dir, "proof.bin")
+
+	steps := []struct {
+		args   []string
+		output string
+	}{
+		{[]string{"compute", "-out", receipt, "10", "20", "30"}, ""},
+		{[]string{"trace", "-receipt", receipt}, "60"},
+		{[]string{"check", "-receipt", receipt}, "ok: 4 steps, total 60"},
+		{[]string{"prove", "-receipt", receipt, "-out", proof, "-hash", "poseidon2"}, "proved total 60 over 4 steps"},
+		{[]string{"verify", "-proof", proof, "-total", "60", "-hash", "poseidon2"}, "ok: total 60 over 4 steps"},
+		{[]string{"inspect", "-proof", proof}, "poseidon2"},
+	}
+
+	for _, step := range steps {
+		code, stdout, stderr := execute(step.args...)
+		if code != 0 {
+			t.Fatalf("%s exited with %d: %s", step.args[0], code, stderr)
+		}
+
+		if !strings.Contains(stdout, step.output) {
+			t.Errorf("%s printed %q, expected it to contain %q", step.args[0], stdout, step.output)
+		}
+	}
+
+	if code, _, _ := execute("verify", "-proof", proof, "-total", "61", "-hash", "poseidon2"); code != 1 {
+		t.Errorf("verify of a wrong total exited with %d, expected 1", code)
+	}
+
+	if code, _, _ := execute("verify", "-proof", proof, "-total", "60"); code != 1 {
+		t.Errorf("verify with other parameters exited with %d, expected 1", code)
+	}
+}
+
+func TestComputePrintsReceipt(t *testing.T) {
+	code, stdout, stderr := execute("compute", "-pad=false", "5", "7")
+	if code != 0 {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+
+	for _, expected := range []string{`"first"`, `"12"`} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("compute printed %q, expected it to contain %s", stdout, expected)
+		}
+	}
+}
+
+func TestComputeFromCSV(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "receipt.csv")
+
+	if err := os.WriteFile(input, []byte("sku,qty,price\nA-1,2,12.34\nB-2,1,0.99\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := execute("compute", "-csv", input)
+	if code != 0 {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+
+	if !strings.Contains(stdout, `"2567"`) {
+		t.Errorf("compute printed %q, expected the total 2567", stdout)
+	}
+
+	if err := os.WriteFile(input, []byte("sku,qty,price\nA-1,2,12.345\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _, stderr = execute("compute", "-csv", input)
+	if code != 1 || !strings.Contains(stderr, "line 2: price") {
+		t.Errorf("compute exited with %d and %q, expected the line error", code, stderr)
+	}
+
+	if code, _, _ = execute("compute", "-csv", input, "1"); code != 2 {
+		t.Errorf("compute with both a file and prices exited with %d, expected 2", code)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no command":      {},
+		"unknown command": {"sum"},
+		"no prices":       {"compute"},
+		"bad price":       {"compute", "ten"},
+		"missing receipt": {"check"},
+		"unknown hash":    {"verify", "-proof", "p", "-total", "1", "-hash", "md5"},
+		"bad blowup":      {"verify", "-proof", "p", "-total", "1", "-blowup", "3"},
+		"unknown flag":    {"inspect", "-x"},
+		"help":            {"prove", "-h"},
+		"no workers":      {"serve", "-workers", "0"},
+	} {
+		if code, _, _ := execute(args...); code != 2 {
+			t.Errorf("%s exited with %d, expected 2", name, code)
+		}
+	}
+
+	if code, _, _ := execute("inspect", "-proof", filepath.Join(t.TempDir(), "missing")); code != 1 {
+		t.Errorf("inspect of a missing file exited with %d, expected 1", code)
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/field/generator"
 	field "github.com/consensys/gnark-crypto/field/generator/config"
@@ -19,4 +22,34 @@ func main() {
 	if err = generator.GenerateFF(fIntegration, "./"); err != nil {
 		log.Fatal(elementName, err)
 	}
+
+	if err = removeUnsafe("vector.go"); err != nil {
+		log.Fatal(elementName, err)
+	}
+}
+
+// removeUnsafe makes the generated vector read its elements into a buffer instead of an unsafe view
+// of its memory, so that the verifier builds without unsafe, e.g. for WebAssembly
+func removeUnsafe(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	source := string(data)
+	for _, replacement := range [][2]string{
+		{"\t\"unsafe\"\n", ""},
+		{
+			"bSlice := unsafe.Slice((*byte)(unsafe.Pointer(&(*vector)[0])), sliceLen*Bytes)",
+			"bSlice := make([]byte, int(sliceLen)*Bytes)",
+		},
+	} {
+		if !strings.Contains(source, replacement[0]) {
+			return fmt.Errorf("%s: %q not found, update removeUnsafe for the generator", path, replacement[0])
+		}
+
+		source = strings.Replace(source, replacement[0], replacement[1], 1)
+	}
+
+	return os.WriteFile(path, []byte(source), 0o644)
 }
@@ -0,0 +1,35 @@
+package keccak
+
+import (
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// wordSize is the size of an EVM word
+const wordSize = 32
+
+// Hasher hashes field elements with Keccak-256 in the layout of the EVM, so that a contract hashes
+// calldata or memory after the one-byte domain tag: every element is a 32-byte big-endian word,
+// i.e. HashElements(e) = keccak256(abi.encodePacked(bytes1(0x00), uint256[] e)),
+// and Merge(l, r) = keccak256(abi.encodePacked(bytes1(0x01), l, r)), see merkle.LeafTag and merkle.NodeTag.
+type Hasher struct{}
+
+// HashElements returns the digest of merkle.LeafTag followed by the elements encoded as 32-byte big-endian words
+func (Hasher) HashElements(elements []*math.PrimeField) merkle.Digest {
+	data := make([]byte, 1+len(elements)*wordSize)
+	data[0] = merkle.LeafTag
+	for i, element := range elements {
+		bytes := element.Bytes()
+		copy(data[1+(i+1)*wordSize-len(bytes):], bytes[:])
+	}
+
+	return Sum256(data)
+}
+
+// Merge returns the digest of merkle.NodeTag followed by the concatenation of two digests
+func (Hasher) Merge(left, right merkle.Digest) merkle.Digest {
+	data := make([]byte, 0, 1+2*merkle.DigestSize)
+	data = append(append(append(data, merkle.NodeTag), left[:]...), right[:]...)
+
+	return Sum256(data)
+}
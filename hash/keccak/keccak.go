@@ -0,0 +1,103 @@
+// Package keccak implements Keccak-256 as used by Ethereum, i.e. the Keccak sponge with the original
+// 0x01 padding instead of the 0x06 padding of SHA3-256, and a Merkle hasher compatible with the EVM.
+//
+// Source: https://keccak.team/files/Keccak-reference-3.0.pdf
+package keccak
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// Size is the size of a Keccak-256 digest in bytes
+	Size = 32
+
+	// rate is the number of bytes absorbed per permutation, 1600 bits minus twice the digest size
+	rate = 200 - 2*Size
+)
+
+// roundConstants are the constants of the iota step of the 24 rounds of Keccak-f[1600]
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotations are the rotation offsets of the rho step for the lane x + 5y
+var rotations = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// permute applies Keccak-f[1600] to the state of 25 lanes indexed by x + 5y
+func permute(a *[25]uint64) {
+	var c [5]uint64
+	var b [25]uint64
+
+	for _, rc := range roundConstants {
+		// θ
+		for x := range 5 {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := range 5 {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[x+y] ^= d
+			}
+		}
+
+		// ρ and π: B[y, 2x + 3y] = rot(A[x, y])
+		for x := range 5 {
+			for y := range 5 {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], rotations[x+5*y])
+			}
+		}
+
+		// χ
+		for y := 0; y < 25; y += 5 {
+			for x := range 5 {
+				a[x+y] = b[x+y] ^ (^b[(x+1)%5+y] & b[(x+2)%5+y])
+			}
+		}
+
+		// ι
+		a[0] ^= rc
+	}
+}
+
+// Sum256 returns the Keccak-256 digest of the data
+func Sum256(data []byte) [Size]byte {
+	var state [25]uint64
+
+	absorb := func(block []byte) {
+		for i := 0; i < rate/8; i++ {
+			state[i] ^= binary.LittleEndian.Uint64(block[8*i:])
+		}
+		permute(&state)
+	}
+
+	for len(data) >= rate {
+		absorb(data[:rate])
+		data = data[rate:]
+	}
+
+	var last [rate]byte
+	copy(last[:], data)
+	last[len(data)] ^= 0x01
+	last[rate-1] ^= 0x80
+	absorb(last[:])
+
+	var digest [Size]byte
+	for i := range Size / 8 {
+		binary.LittleEndian.PutUint64(digest[8*i:], state[i])
+	}
+
+	return digest
+}
@@ -0,0 +1,77 @@
+package poseidon2
+
+import (
+	"math/big"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// grain is the Grain LFSR used by the Poseidon reference implementation to generate round constants
+type grain struct {
+	state [80]bool
+}
+
+// newGrain initializes the LFSR with the parameters of a permutation over a prime field with the x^α S-box
+func newGrain(fieldBits, width, fullRounds, partialRounds int) *grain {
+	g := new(grain)
+
+	pos := 0
+	push := func(value, bits int) {
+		for i := bits - 1; i >= 0; i-- {
+			g.state[pos] = (value>>i)&1 == 1
+			pos++
+		}
+	}
+
+	push(1, 2) // prime field
+	push(0, 4) // x^α S-box
+	push(fieldBits, 12)
+	push(width, 12)
+	push(fullRounds, 10)
+	push(partialRounds, 10)
+	push(1<<30-1, 30)
+
+	for range 160 {
+		g.next()
+	}
+
+	return g
+}
+
+// next advances the LFSR by one step and returns the new bit
+func (g *grain) next() bool {
+	bit := g.state[62] != g.state[51] != g.state[38] != g.state[23] != g.state[13] != g.state[0]
+	copy(g.state[:], g.state[1:])
+	g.state[79] = bit
+
+	return bit
+}
+
+// bit returns the next output bit, using the self-shrinking rule:
+// bits are taken in pairs and the second one is output only if the first one is set
+func (g *grain) bit() bool {
+	for !g.next() {
+		g.next()
+	}
+
+	return g.next()
+}
+
+// element returns the next field element, sampled by rejection from 64-bit big-endian integers
+func (g *grain) element() *math.PrimeField {
+	modulus := new(big.Int).SetUint64(math.Modulus)
+
+	for {
+		value := new(big.Int)
+		for range 64 {
+			value.Lsh(value, 1)
+			if g.bit() {
+				value.SetBit(value, 0, 1)
+			}
+		}
+
+		if value.Cmp(modulus) < 0 {
+			return new(math.PrimeField).SetBigInt(value)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package poseidon2 implements the Poseidon2 permutation and sponge over the Goldilocks field
+// with the width 8 and width 12 parameters of the reference implementation.
+//
+// Source: https://eprint.iacr.org/2023/323 and https://github.com/HorizenLabs/poseidon2
+package poseidon2
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+const (
+	// FullRounds is the number of full rounds, half of them before and half after the partial rounds
+	FullRounds = 8
+
+	// PartialRounds is the number of partial rounds for widths 8 and 12
+	PartialRounds = 22
+
+	// Alpha is the degree of the S-box
+	Alpha = 7
+)
+
+// internalDiagonal8 is MAT_DIAG8_M_1 of the reference implementation, the diagonal of the internal matrix
+// of width 8 minus the identity
+var internalDiagonal8 = [8]uint64{
+	0xa98811a1fed4e3a5, 0x1cc48b54f377e2a0, 0xe40cd4f6c5609a26, 0x11de79ebca97a4a3,
+	0x9177c73d8b7e929c, 0x2a6fe8085797e791, 0x3de6e93329f8d5ad, 0x3f7af9125da962fe,
+}
+
+// internalDiagonal12 is MAT_DIAG12_M_1 of the reference implementation, the diagonal of the internal matrix
+// of width 12 minus the identity
+var internalDiagonal12 = [12]uint64{
+	0xc3b6c08e23ba9300, 0xd84b5de94a324fb6, 0x0d0c371c5b35b84f, 0x7964f570e7188037,
+	0x5daf18bbd996604b, 0x6743bc47b9595257, 0x5528b9362c59bb70, 0xac45e25b7127b68b,
+	0xa2077d7dfbb606b5, 0xf3faac6faee378ae, 0x0c6388b51545e883, 0xd27dbb6944917b60,
+}
+
+// Permutation is the Poseidon2 permutation of a given width
+type Permutation struct {
+	width int
+
+	// roundConstants holds width constants per full round and one per partial round
+	roundConstants [][]*math.PrimeField
+
+	// internalDiagonal holds the diagonal of the internal matrix minus the identity
+	internalDiagonal []*math.PrimeField
+}
+
+var permutations = map[int]*Permutation{
+	8:  newPermutation(8, internalDiagonal8[:]),
+	12: newPermutation(12, internalDiagonal12[:]),
+}
+
+// New returns the Poseidon2 permutation of width 8 or 12, the widths of the reference parameters for Goldilocks
+func New(width int) (*Permutation, error) {
+	p, ok := permutations[width]
+	if !ok {
+		return nil, fmt.Errorf("unsupported poseidon2 width %d", width)
+	}
+
+	return p, nil
+}
+
+// newPermutation generates the round constants of the permutation with the Grain LFSR as in the reference implementation:
+// width constants per full round and a single constant per partial round, in round order
+func newPermutation(width int, diagonal []uint64) *Permutation {
+	g := newGrain(64, width, FullRounds, PartialRounds)
+
+	roundConstants := make([][]*math.PrimeField, FullRounds+PartialRounds)
+	for r := range roundConstants {
+		n := width
+		if r >= FullRounds/2 && r < FullRounds/2+PartialRounds {
+			n = 1
+		}
+
+		roundConstants[r] = make([]*math.PrimeField, n)
+		for i := range roundConstants[r] {
+			roundConstants[r][i] = g.element()
+		}
+	}
+
+	internalDiagonal := make([]*math.PrimeField, width)
+	for i, d := range diagonal {
+		internalDiagonal[i] = math.NewPrimeFieldUint64(d)
+	}
+
+	return &Permutation{
+		width:            width,
+		roundConstants:   roundConstants,
+		internalDiagonal: internalDiagonal,
+	}
+}
+
+// Width returns the number of elements in the state
+func (p *Permutation) Width() int {
+	return p.width
+}
+
+// Permute applies the permutation to the state in place
+func (p *Permutation) Permute(state []*math.PrimeField) {
+	if len(state) != p.width {
+		panic(fmt.Sprintf("poseidon2: state must have %d elements, got %d", p.width, len(state)))
+	}
+
+	for i := range state {
+		state[i] = state[i].Copy()
+	}
+
+	p.externalLayer(state)
+
+	for r := 0; r < FullRounds/2; r++ {
+		p.fullRound(state, r)
+	}
+
+	for r := FullRounds / 2; r < FullRounds/2+PartialRounds; r++ {
+		state[0].Add(state[0], p.roundConstants[r][0])
+		sbox(state[0])
+		p.internalLayer(state)
+	}
+
+	for r := FullRounds/2 + PartialRounds; r < FullRounds+PartialRounds; r++ {
+		p.fullRound(state, r)
+	}
+}
+
+func (p *Permutation) fullRound(state []*math.PrimeField, round int) {
+	for i := range state {
+		state[i].Add(state[i], p.roundConstants[round][i])
+		sbox(state[i])
+	}
+
+	p.externalLayer(state)
+}
+
+// sbox raises x to the power Alpha in place
+func sbox(x *math.PrimeField) {
+	x2 := new(math.PrimeField).Square(x)
+	x3 := new(math.PrimeField).Mul(x2, x)
+	x4 := new(math.PrimeField).Square(x2)
+	x.Mul(x3, x4)
+}
+
+// externalLayer multiplies the state by circ(2·M4, M4, ..., M4)
+func (p *Permutation) externalLayer(state []*math.PrimeField) {
+	for i := 0; i < p.width; i += 4 {
+		mulM4(state[i : i+4])
+	}
+
+	sums := make([]*math.PrimeField, 4)
+	for j := range sums {
+		sums[j] = new(math.PrimeField).SetZero()
+		for i := j; i < p.width; i += 4 {
+			sums[j].Add(sums[j], state[i])
+		}
+	}
+
+	for i := range state {
+		state[i].Add(state[i], sums[i%4])
+	}
+}
+
+// mulM4 multiplies four elements in place by
+//
+//	[5 7 1 3]
+//	[4 6 1 1]
+//	[1 3 5 7]
+//	[1 1 4 6]
+func mulM4(x []*math.PrimeField) {
+	f := new(math.PrimeField)
+
+	t0 := f.Add(x[0], x[1])
+	t1 := f.Add(x[2], x[3])
+	t2 := f.Add(f.Add(x[1], x[1]), t1)
+	t3 := f.Add(f.Add(x[3], x[3]), t0)
+	t4 := f.Add(f.Mul(t1, math.NewPrimeField(4)), t3)
+	t5 := f.Add(f.Mul(t0, math.NewPrimeField(4)), t2)
+	t6 := f.Add(t3, t5)
+	t7 := f.Add(t2, t4)
+
+	x[0].Set(t6)
+	x[1].Set(t5)
+	x[2].Set(t7)
+	x[3].Set(t4)
+}
+
+// internalLayer multiplies the state by J + diag(internalDiagonal)
+func (p *Permutation) internalLayer(state []*math.PrimeField) {
+	sum := new(math.PrimeField).SetZero()
+	for _, x := range state {
+		sum.Add(sum, x)
+	}
+
+	for i, x := range state {
+		x.Add(new(math.PrimeField).Mul(x, p.internalDiagonal[i]), sum)
+	}
+}
+
+// Hash applies the permutation to a copy of the input and returns it
+func (p *Permutation) Hash(input []*math.PrimeField) []*math.PrimeField {
+	state := make([]*math.PrimeField, len(input))
+	copy(state, input)
+
+	p.Permute(state)
+
+	return state
+}
@@ -0,0 +1,126 @@
+package poseidon2
+
+import (
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// Capacity is the number of state elements that are never absorbed into or squeezed from
+const Capacity = 4
+
+// Sponge absorbs and squeezes field elements with the Poseidon2 permutation.
+// The rate is the permutation width minus Capacity.
+type Sponge struct {
+	permutation *Permutation
+	state       []*math.PrimeField
+
+	// pos is the next rate position to absorb into or squeeze from
+	pos int
+
+	squeezing bool
+}
+
+// NewSponge creates a sponge with an all-zero state
+func NewSponge(permutation *Permutation) *Sponge {
+	state := make([]*math.PrimeField, permutation.Width())
+	for i := range state {
+		state[i] = new(math.PrimeField).SetZero()
+	}
+
+	return &Sponge{permutation: permutation, state: state}
+}
+
+func (s *Sponge) rate() int {
+	return s.permutation.Width() - Capacity
+}
+
+// Absorb adds elements into the rate part of the state, permuting whenever it is full
+func (s *Sponge) Absorb(elements []*math.PrimeField) {
+	if s.squeezing {
+		s.squeezing = false
+		s.pos = 0
+	}
+
+	for _, element := range elements {
+		if s.pos == s.rate() {
+			s.permutation.Permute(s.state)
+			s.pos = 0
+		}
+
+		s.state[s.pos] = new(math.PrimeField).Add(s.state[s.pos], element)
+		s.pos++
+	}
+}
+
+// Squeeze returns n elements from the rate part of the state.
+// The first squeeze after absorbing pads the input with a single one followed by zeros.
+func (s *Sponge) Squeeze(n int) []*math.PrimeField {
+	if !s.squeezing {
+		if s.pos == s.rate() {
+			s.permutation.Permute(s.state)
+			s.pos = 0
+		}
+
+		s.state[s.pos] = new(math.PrimeField).Add(s.state[s.pos], new(math.PrimeField).SetOne())
+		s.permutation.Permute(s.state)
+
+		s.squeezing = true
+		s.pos = 0
+	}
+
+	output := make([]*math.PrimeField, n)
+	for i := range output {
+		if s.pos == s.rate() {
+			s.permutation.Permute(s.state)
+			s.pos = 0
+		}
+
+		output[i] = s.state[s.pos].Copy()
+		s.pos++
+	}
+
+	return output
+}
+
+// Hasher hashes field elements with a Poseidon2 sponge, digests are 4 field elements
+type Hasher struct {
+	permutation *Permutation
+}
+
+// NewHasher creates a hasher over the Poseidon2 permutation of the given width
+func NewHasher(width int) (*Hasher, error) {
+	permutation, err := New(width)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hasher{permutation: permutation}, nil
+}
+
+// HashElements absorbs elements into a fresh sponge and squeezes a digest
+func (h *Hasher) HashElements(elements []*math.PrimeField) merkle.Digest {
+	sponge := NewSponge(h.permutation)
+	sponge.Absorb(elements)
+
+	return ElementsToDigest(sponge.Squeeze(merkle.DigestSize / math.PrimeFieldBytes))
+}
+
+// Merge absorbs the elements of both digests into a fresh sponge whose last capacity element is merkle.NodeTag
+// and squeezes a digest, HashElements leaves it at merkle.LeafTag
+func (h *Hasher) Merge(left, right merkle.Digest) merkle.Digest {
+	sponge := NewSponge(h.permutation)
+	sponge.state[len(sponge.state)-1] = math.NewPrimeField(merkle.NodeTag)
+	sponge.Absorb(append(DigestToElements(left), DigestToElements(right)...))
+
+	return ElementsToDigest(sponge.Squeeze(merkle.DigestSize / math.PrimeFieldBytes))
+}
+
+// ElementsToDigest encodes 4 field elements as a digest, see merkle.ElementsToDigest
+func ElementsToDigest(elements []*math.PrimeField) merkle.Digest {
+	return merkle.ElementsToDigest(elements)
+}
+
+// DigestToElements decodes a digest into 4 field elements, see merkle.DigestToElements
+func DigestToElements(digest merkle.Digest) []*math.PrimeField {
+	return merkle.DigestToElements(digest)
+}
@@ -0,0 +1,34 @@
+package rescue
+
+import (
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// Hasher hashes field elements with the Rescue hash, digests are DigestElements field elements.
+// Merging two digests absorbs exactly Rate elements, so each tree node costs a single permutation.
+type Hasher struct{}
+
+// HashElements returns the digest of the elements
+func (Hasher) HashElements(elements []*math.PrimeField) merkle.Digest {
+	return merkle.ElementsToDigest(Hash(elements))
+}
+
+// Merge returns the digest of the elements of both digests, absorbed into NodeState with a single permutation
+func (Hasher) Merge(left, right merkle.Digest) merkle.Digest {
+	state := NodeState()
+	for i, x := range append(merkle.DigestToElements(left), merkle.DigestToElements(right)...) {
+		state[i] = x
+	}
+
+	return merkle.ElementsToDigest(Permute(state)[:DigestElements])
+}
+
+// NodeState returns the sponge state before merging two digests: the state before absorbing Rate elements
+// with merkle.NodeTag in the second capacity element, which HashElements leaves at merkle.LeafTag
+func NodeState() []*math.PrimeField {
+	state := InitialState(Rate)
+	state[Rate+1] = math.NewPrimeField(merkle.NodeTag)
+
+	return state
+}
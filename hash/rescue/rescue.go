@@ -0,0 +1,249 @@
+// Package rescue implements a Rescue permutation and hash over the Goldilocks field with custom parameters.
+//
+// The round function is the one of Rescue-Prime: a forward S-box x^α, the MDS matrix and constants,
+// then the backward S-box x^(1/α), the MDS matrix and constants. The parameters are not the ones
+// of the specification, so the hash is neither Rescue-Prime nor RPO and has no published test vectors:
+// the round constants are derived from SHA-256 instead of SHAKE256 and the MDS matrix is the circulant
+// matrix of RPO, which the specification does not derive for these parameters.
+//
+// Source: https://eprint.iacr.org/2020/1143
+package rescue
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+const (
+	// Width is the number of elements in the state
+	Width = 12
+
+	// Capacity is the number of state elements that are never absorbed into or squeezed from
+	Capacity = 4
+
+	// Rate is the number of elements absorbed per permutation
+	Rate = Width - Capacity
+
+	// DigestElements is the number of elements in a digest
+	DigestElements = 4
+
+	// Rounds is the number of rounds of the permutation
+	Rounds = 7
+
+	// Alpha is the degree of the forward S-box
+	Alpha = 7
+)
+
+var (
+	// alphaInv is the inverse of Alpha modulo Modulus - 1, the exponent of the backward S-box
+	alphaInv = new(big.Int).ModInverse(big.NewInt(Alpha), new(big.Int).SetUint64(math.Modulus-1))
+
+	// mdsFirstRow is the first row of the circulant MDS matrix, taken from RPO
+	mdsFirstRow = []int64{7, 23, 8, 26, 13, 10, 9, 7, 6, 22, 21, 8}
+
+	// MDS is the MDS matrix of the permutation
+	MDS = circulant(mdsFirstRow)
+
+	// MDSInv is the inverse of the MDS matrix
+	MDSInv = invert(MDS)
+
+	// RoundConstants holds 2·Width constants per round: the first half is added after
+	// the forward S-box, the second half after the backward S-box
+	RoundConstants = roundConstants()
+)
+
+// roundConstants derives the round constants from SHA-256 of the seed and a counter,
+// rejecting 64-bit little-endian values that are not below the modulus.
+// This is a custom derivation, the specification expands its seed with SHAKE256.
+func roundConstants() [][]*math.PrimeField {
+	seed := []byte(fmt.Sprintf("Rescue-Custom(p=%d,m=%d,c=%d,N=%d)", math.Modulus, Width, Capacity, Rounds))
+
+	counter := uint32(0)
+	var block []byte
+
+	next := func() *math.PrimeField {
+		for {
+			if len(block) == 0 {
+				sum := sha256.Sum256(binary.LittleEndian.AppendUint32(append([]byte{}, seed...), counter))
+				block = sum[:]
+				counter++
+			}
+
+			value := binary.LittleEndian.Uint64(block)
+			block = block[8:]
+
+			if value < math.Modulus {
+				return math.NewPrimeFieldUint64(value)
+			}
+		}
+	}
+
+	constants := make([][]*math.PrimeField, Rounds)
+	for r := range constants {
+		constants[r] = make([]*math.PrimeField, 2*Width)
+		for i := range constants[r] {
+			constants[r][i] = next()
+		}
+	}
+
+	return constants
+}
+
+func circulant(firstRow []int64) [][]*math.PrimeField {
+	n := len(firstRow)
+
+	matrix := make([][]*math.PrimeField, n)
+	for i := range matrix {
+		matrix[i] = make([]*math.PrimeField, n)
+		for j := range matrix[i] {
+			matrix[i][j] = math.NewPrimeField(firstRow[(j-i+n)%n])
+		}
+	}
+
+	return matrix
+}
+
+// invert inverts a square matrix with Gauss-Jordan elimination
+func invert(matrix [][]*math.PrimeField) [][]*math.PrimeField {
+	n := len(matrix)
+
+	a := make([][]*math.PrimeField, n)
+	for i := range a {
+		a[i] = make([]*math.PrimeField, 2*n)
+		for j := range n {
+			a[i][j] = matrix[i][j].Copy()
+			a[i][n+j] = new(math.PrimeField).SetZero()
+		}
+		a[i][n+i].SetOne()
+	}
+
+	for col := range n {
+		pivot := col
+		for pivot < n && a[pivot][col].IsZero() {
+			pivot++
+		}
+		if pivot == n {
+			panic("rescue: matrix is not invertible")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		inv := new(math.PrimeField).Inv(a[col][col])
+		for j := range a[col] {
+			a[col][j] = new(math.PrimeField).Mul(a[col][j], inv)
+		}
+
+		for i := range n {
+			if i == col || a[i][col].IsZero() {
+				continue
+			}
+
+			factor := a[i][col].Copy()
+			for j := range a[i] {
+				a[i][j] = new(math.PrimeField).Sub(a[i][j], new(math.PrimeField).Mul(factor, a[col][j]))
+			}
+		}
+	}
+
+	inverse := make([][]*math.PrimeField, n)
+	for i := range inverse {
+		inverse[i] = a[i][n:]
+	}
+
+	return inverse
+}
+
+// MulMatrix multiplies a matrix by a vector
+func MulMatrix(matrix [][]*math.PrimeField, vector []*math.PrimeField) []*math.PrimeField {
+	output := make([]*math.PrimeField, len(matrix))
+	for i, row := range matrix {
+		output[i] = new(math.PrimeField).SetZero()
+		for j, m := range row {
+			output[i] = new(math.PrimeField).Add(output[i], new(math.PrimeField).Mul(m, vector[j]))
+		}
+	}
+
+	return output
+}
+
+// PowAlpha raises every element of the state to the power Alpha
+func PowAlpha(state []*math.PrimeField) []*math.PrimeField {
+	output := make([]*math.PrimeField, len(state))
+	for i, x := range state {
+		output[i] = new(math.PrimeField).Exp(x, big.NewInt(Alpha))
+	}
+
+	return output
+}
+
+// PowAlphaInv raises every element of the state to the power 1/Alpha
+func PowAlphaInv(state []*math.PrimeField) []*math.PrimeField {
+	output := make([]*math.PrimeField, len(state))
+	for i, x := range state {
+		output[i] = new(math.PrimeField).Exp(x, alphaInv)
+	}
+
+	return output
+}
+
+// AddConstants adds the constants to the state element-wise
+func AddConstants(state, constants []*math.PrimeField) []*math.PrimeField {
+	output := make([]*math.PrimeField, len(state))
+	for i := range state {
+		output[i] = new(math.PrimeField).Add(state[i], constants[i])
+	}
+
+	return output
+}
+
+// Round applies round r of the permutation
+func Round(state []*math.PrimeField, r int) []*math.PrimeField {
+	state = AddConstants(MulMatrix(MDS, PowAlpha(state)), RoundConstants[r][:Width])
+	return AddConstants(MulMatrix(MDS, PowAlphaInv(state)), RoundConstants[r][Width:])
+}
+
+// Permute returns the Rescue permutation of the state
+func Permute(state []*math.PrimeField) []*math.PrimeField {
+	if len(state) != Width {
+		panic(fmt.Sprintf("rescue: state must have %d elements, got %d", Width, len(state)))
+	}
+
+	for r := range Rounds {
+		state = Round(state, r)
+	}
+
+	return state
+}
+
+// Hash returns the Rescue digest of the input.
+//
+// The first capacity element is initialized to the input length for domain separation,
+// the input is absorbed in blocks of Rate elements, the last block is padded with zeros.
+func Hash(input []*math.PrimeField) []*math.PrimeField {
+	state := InitialState(len(input))
+
+	for start := 0; start < len(input) || start == 0; start += Rate {
+		end := min(start+Rate, len(input))
+		for i, x := range input[start:end] {
+			state[i] = new(math.PrimeField).Add(state[i], x)
+		}
+
+		state = Permute(state)
+	}
+
+	return state[:DigestElements]
+}
+
+// InitialState returns the sponge state before absorbing an input of the given length
+func InitialState(length int) []*math.PrimeField {
+	state := make([]*math.PrimeField, Width)
+	for i := range state {
+		state[i] = new(math.PrimeField).SetZero()
+	}
+	state[Rate] = math.NewPrimeField(int64(length))
+
+	return state
+}
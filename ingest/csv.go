@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSV columns, matched case-insensitively against the header
+var csvColumns = []string{"sku", "qty", "price"}
+
+// ReadCSV reads a receipt from CSV with a header naming the columns sku, qty and price in any order,
+// other columns are ignored. Line numbers in errors are the lines of the input, the header is line 1.
+func ReadCSV(r io.Reader, scale int) (*Receipt, error) {
+	b, err := newBuilder(scale)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("receipt has no header")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	index := make(map[string]int, len(csvColumns))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	for _, name := range csvColumns {
+		if _, ok := index[name]; !ok {
+			return nil, &LineError{Line: 1, Field: name, Err: fmt.Errorf("column is missing from the header")}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			b.errs = append(b.errs, csvError(err))
+			return b.result()
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i := index[name]; i < len(record) {
+				return record[i]
+			}
+
+			return ""
+		}
+
+		b.add(line, field("sku"), field("qty"), field("price"))
+	}
+
+	return b.result()
+}
+
+// csvError converts an error of the CSV reader into a line error
+func csvError(err error) *LineError {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &LineError{Line: parseErr.Line, Err: parseErr.Err}
+	}
+
+	return &LineError{Err: err}
+}
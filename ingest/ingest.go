@@ -0,0 +1,281 @@
+// Package ingest reads receipts as they arrive from point-of-sale systems, lines of (sku, quantity, unit price)
+// with decimal prices, and converts them into the prices of an air.Receipt.
+//
+// Prices are converted to integer minor units with a fixed scale, e.g. 12.34 with scale 2 is 1234,
+// and every line amount and the running total must stay below the field modulus, so that the proven total
+// is the total of the receipt rather than its remainder modulo the field.
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+)
+
+// DefaultScale is the number of decimal places of the minor unit of most currencies
+const DefaultScale = 2
+
+// MaxScale is the largest scale whose unit 10^scale fits into the field
+const MaxScale = 18
+
+var (
+	// ErrSyntax is returned for amounts and quantities that are not decimal numbers
+	ErrSyntax = errors.New("invalid number")
+
+	// ErrPrecision is returned for prices with more decimal places than the scale
+	ErrPrecision = errors.New("too many decimal places")
+
+	// ErrOverflow is returned for amounts that do not fit into the field
+	ErrOverflow = errors.New("amount overflows the field")
+
+	// ErrMissing is returned for empty required fields
+	ErrMissing = errors.New("missing value")
+)
+
+// Line is a line of a receipt
+type Line struct {
+	SKU      string
+	Quantity uint64
+
+	// UnitPrice is the price of one item in minor units
+	UnitPrice uint64
+}
+
+// Amount returns the price of the line in minor units, Quantity·UnitPrice, which the parsers keep below the modulus
+func (l Line) Amount() uint64 {
+	return l.Quantity * l.UnitPrice
+}
+
+// Receipt is a parsed receipt
+type Receipt struct {
+	// Scale is the number of decimal places of the minor unit the prices are expressed in
+	Scale int
+
+	Lines []Line
+}
+
+// Prices returns the amount of every line as a field element
+func (r *Receipt) Prices() []*math.PrimeField {
+	prices := make([]*math.PrimeField, len(r.Lines))
+	for i, line := range r.Lines {
+		prices[i] = math.NewPrimeFieldUint64(line.Amount())
+	}
+
+	return prices
+}
+
+// Total returns the total of the receipt in minor units
+func (r *Receipt) Total() uint64 {
+	total := uint64(0)
+	for _, line := range r.Lines {
+		total += line.Amount()
+	}
+
+	return total
+}
+
+// Compute returns the receipt trace of the line amounts padded to a power of two
+func (r *Receipt) Compute() *air.Receipt {
+	return air.ComputePadded(r.Prices())
+}
+
+// FormatAmount formats an amount in minor units as a decimal with scale decimal places
+func FormatAmount(amount uint64, scale int) string {
+	digits := strconv.FormatUint(amount, 10)
+	if scale <= 0 {
+		return digits
+	}
+
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	return digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// ParseAmount parses a non-negative decimal such as "12.34" into minor units with the given scale.
+// Amounts with more decimal places than the scale are rejected instead of rounded.
+func ParseAmount(text string, scale int) (uint64, error) {
+	if scale < 0 || scale > MaxScale {
+		return 0, fmt.Errorf("scale must be between 0 and %d, got %d", MaxScale, scale)
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	if len(strings.TrimRight(fraction, "0")) > scale {
+		return 0, fmt.Errorf("%w: %q at scale %d", ErrPrecision, text, scale)
+	}
+
+	fraction += strings.Repeat("0", scale-min(len(fraction), scale))
+	fraction = fraction[:scale]
+
+	value := uint64(0)
+	for _, digit := range whole + fraction {
+		hi, lo := bits.Mul64(value, 10)
+		lo, carry := bits.Add64(lo, uint64(digit-'0'), 0)
+		if hi != 0 || carry != 0 || lo >= math.Modulus {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, text)
+		}
+
+		value = lo
+	}
+
+	return value, nil
+}
+
+// parseQuantity parses a positive integer quantity
+func parseQuantity(text string) (uint64, error) {
+	if !isDigits(text) || text == "" {
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	quantity, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, text)
+	}
+
+	if quantity == 0 {
+		return 0, fmt.Errorf("%w: quantity must be positive", ErrSyntax)
+	}
+
+	return quantity, nil
+}
+
+func isDigits(text string) bool {
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LineError is an error in a field of a line of the input. Line is the 1-based line of a CSV file
+// or the 1-based index of a line in a JSON receipt.
+type LineError struct {
+	Line  int
+	Field string
+	Err   error
+}
+
+func (e *LineError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Field, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Errors are all line errors of an input, the parsers report every invalid line instead of stopping at the first one
+type Errors []*LineError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the line errors, so that errors.Is and errors.As look into every line
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// builder collects the lines and the errors of an input
+type builder struct {
+	receipt *Receipt
+	total   uint64
+	errs    Errors
+}
+
+func newBuilder(scale int) (*builder, error) {
+	if scale < 0 || scale > MaxScale {
+		return nil, fmt.Errorf("scale must be between 0 and %d, got %d", MaxScale, scale)
+	}
+
+	return &builder{receipt: &Receipt{Scale: scale}}, nil
+}
+
+func (b *builder) fail(line int, field string, err error) {
+	b.errs = append(b.errs, &LineError{Line: line, Field: field, Err: err})
+}
+
+// add parses the fields of a line and appends it if the line amount and the running total fit into the field
+func (b *builder) add(line int, sku, quantity, price string) {
+	failed := len(b.errs)
+
+	if strings.TrimSpace(sku) == "" {
+		b.fail(line, "sku", ErrMissing)
+	}
+
+	parsed := Line{SKU: strings.TrimSpace(sku)}
+
+	var err error
+	if quantity = strings.TrimSpace(quantity); quantity == "" {
+		b.fail(line, "qty", ErrMissing)
+	} else if parsed.Quantity, err = parseQuantity(quantity); err != nil {
+		b.fail(line, "qty", err)
+	}
+
+	if price = strings.TrimSpace(price); price == "" {
+		b.fail(line, "price", ErrMissing)
+	} else if parsed.UnitPrice, err = ParseAmount(price, b.receipt.Scale); err != nil {
+		b.fail(line, "price", err)
+	}
+
+	if len(b.errs) != failed {
+		return
+	}
+
+	hi, amount := bits.Mul64(parsed.Quantity, parsed.UnitPrice)
+	if hi != 0 || amount >= math.Modulus {
+		b.fail(line, "", fmt.Errorf("%w: %d × %s", ErrOverflow, parsed.Quantity, price))
+		return
+	}
+
+	total, carry := bits.Add64(b.total, amount, 0)
+	if carry != 0 || total >= math.Modulus {
+		b.fail(line, "", fmt.Errorf("%w: running total", ErrOverflow))
+		return
+	}
+
+	b.total = total
+	b.receipt.Lines = append(b.receipt.Lines, parsed)
+}
+
+// result returns the receipt, or all line errors if there are any
+func (b *builder) result() (*Receipt, error) {
+	if len(b.errs) != 0 {
+		return nil, b.errs
+	}
+
+	if len(b.receipt.Lines) == 0 {
+		return nil, fmt.Errorf("receipt has no lines")
+	}
+
+	return b.receipt, nil
+}
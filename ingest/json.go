@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonReceipt is the JSON receipt {"lines": [{"sku": "A-1", "qty": 2, "price": "12.34"}, ...]}
+type jsonReceipt struct {
+	Lines []struct {
+		SKU      json.RawMessage `json:"sku"`
+		Quantity json.RawMessage `json:"qty"`
+		Price    json.RawMessage `json:"price"`
+	} `json:"lines"`
+}
+
+// ReadJSON reads a receipt from a JSON object with a list of lines, each with a sku, a qty and a price.
+// Quantities and prices may be JSON numbers or decimal strings, strings keep the exact decimal places
+// of prices, which is what point-of-sale systems usually send. Line numbers in errors are 1-based indices of lines.
+func ReadJSON(r io.Reader, scale int) (*Receipt, error) {
+	b, err := newBuilder(scale)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded jsonReceipt
+	if err = json.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode receipt: %w", err)
+	}
+
+	for i, line := range decoded.Lines {
+		fields := make([]string, 3)
+		for k, raw := range []json.RawMessage{line.SKU, line.Quantity, line.Price} {
+			text, err := jsonText(raw)
+			if err != nil {
+				b.fail(i+1, csvColumns[k], err)
+				continue
+			}
+
+			fields[k] = text
+		}
+
+		if len(b.errs) == 0 || b.errs[len(b.errs)-1].Line != i+1 {
+			b.add(i+1, fields[0], fields[1], fields[2])
+		}
+	}
+
+	return b.result()
+}
+
+// jsonText returns the text of a string or a number, and an empty text for missing values and null
+func jsonText(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	if raw[0] == '"' {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return "", err
+		}
+
+		return text, nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return "", fmt.Errorf("%w: must be a string or a number", ErrSyntax)
+	}
+
+	return number.String(), nil
+}
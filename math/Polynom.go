@@ -369,18 +369,7 @@ func (p *Polynom) Subslice(start int, step int) *Polynom {
 	return NewPolynom(result)
 }
 
+// String returns the polynom in a human-readable form, e.g. "5 + 3x + 2x^2"
 func (p *Polynom) String() string {
-	output := ""
-
-	for i, aCoeff := range p.Coefficients {
-		if aCoeff != nil {
-			output += aCoeff.String()
-			if i != 0 {
-				output += "x^" + string(rune(i))
-			}
-			output += " + "
-		}
-	}
-
-	return output
+	return PolynomFormat{}.Format(p)
 }
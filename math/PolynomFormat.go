@@ -0,0 +1,210 @@
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxParseDegree is the largest degree accepted by Parse, which allocates a coefficient per degree
+const MaxParseDegree = 1 << 20
+
+// PolynomFormat describes how a polynom is printed and parsed
+type PolynomFormat struct {
+	// Variable is the name of the indeterminate, "x" if empty
+	Variable string
+
+	// Signed prints coefficients above (Modulus - 1) / 2 as negative numbers,
+	// e.g. Modulus - 3 is printed as "-3" instead of "18446744069414584318"
+	Signed bool
+}
+
+// ParsePolynom parses a polynom written as "5 + 3x + 2x^2"
+func ParsePolynom(s string) (*Polynom, error) {
+	return PolynomFormat{}.Parse(s)
+}
+
+func (f PolynomFormat) variable() string {
+	if f.Variable == "" {
+		return "x"
+	}
+
+	return f.Variable
+}
+
+// Format returns the text representation of a polynom.
+// Zero (and nil) coefficients are skipped, the zero polynom is printed as "0".
+func (f PolynomFormat) Format(p *Polynom) string {
+	var sb strings.Builder
+
+	half := (Modulus - 1) / 2
+
+	for i, aCoeff := range p.Coefficients {
+		if aCoeff == nil || aCoeff.IsZero() {
+			continue
+		}
+
+		value := aCoeff.Uint64()
+		negative := f.Signed && value > half
+		if negative {
+			value = Modulus - value
+		}
+
+		switch {
+		case sb.Len() == 0 && negative:
+			sb.WriteString("-")
+		case sb.Len() == 0:
+		case negative:
+			sb.WriteString(" - ")
+		default:
+			sb.WriteString(" + ")
+		}
+
+		if value != 1 || i == 0 {
+			sb.WriteString(strconv.FormatUint(value, 10))
+		}
+
+		if i > 0 {
+			sb.WriteString(f.variable())
+		}
+
+		if i > 1 {
+			sb.WriteString("^" + strconv.Itoa(i))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "0"
+	}
+
+	return sb.String()
+}
+
+// Parse parses the text representation of a polynom.
+//
+// Terms are separated by '+' or '-' and have the form "c", "cx", "c*x", "x^k" or "c*x^k".
+// Terms of the same degree are summed up, coefficients must be below the modulus
+// and exponents at most MaxParseDegree.
+// The resulting polynom has no trailing zero coefficients, except for the zero polynom, which is [0].
+func (f PolynomFormat) Parse(s string) (*Polynom, error) {
+	src := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	if src == "" {
+		return nil, fmt.Errorf("parse polynom: empty input")
+	}
+
+	coefficients := make(map[int]*PrimeField)
+	maxDegree := 0
+
+	for pos := 0; pos < len(src); {
+		negative := false
+		if src[pos] == '+' || src[pos] == '-' {
+			negative = src[pos] == '-'
+			pos++
+		} else if pos != 0 {
+			return nil, fmt.Errorf("parse polynom: expected '+' or '-' at offset %d in %q", pos, s)
+		}
+
+		end := pos
+		for end < len(src) && src[end] != '+' && src[end] != '-' {
+			end++
+		}
+
+		coeff, degree, err := f.parseTerm(src[pos:end])
+		if err != nil {
+			return nil, fmt.Errorf("parse polynom: %w", err)
+		}
+
+		if negative {
+			coeff = new(PrimeField).Neg(coeff)
+		}
+
+		if acc, ok := coefficients[degree]; ok {
+			coefficients[degree] = new(PrimeField).Add(acc, coeff)
+		} else {
+			coefficients[degree] = coeff
+		}
+
+		maxDegree = max(maxDegree, degree)
+		pos = end
+	}
+
+	output := make([]*PrimeField, maxDegree+1)
+	for i := range output {
+		if coeff, ok := coefficients[i]; ok {
+			output[i] = coeff
+		} else {
+			output[i] = new(PrimeField).SetZero()
+		}
+	}
+
+	for len(output) > 1 && output[len(output)-1].IsZero() {
+		output = output[:len(output)-1]
+	}
+
+	return NewPolynom(output), nil
+}
+
+// parseTerm parses a single unsigned term, returning its coefficient and degree
+func (f PolynomFormat) parseTerm(term string) (*PrimeField, int, error) {
+	if term == "" {
+		return nil, 0, fmt.Errorf("empty term")
+	}
+
+	variable := f.variable()
+
+	idx := strings.Index(term, variable)
+	if idx < 0 {
+		coeff, err := parseCoefficient(term)
+		return coeff, 0, err
+	}
+
+	coeff := new(PrimeField).SetOne()
+	if idx > 0 {
+		var err error
+		if coeff, err = parseCoefficient(strings.TrimSuffix(term[:idx], "*")); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	rest := term[idx+len(variable):]
+	if rest == "" {
+		return coeff, 1, nil
+	}
+
+	if !strings.HasPrefix(rest, "^") {
+		return nil, 0, fmt.Errorf("unexpected %q after %q in term %q", rest, variable, term)
+	}
+
+	degree, err := strconv.Atoi(rest[1:])
+	if err != nil || degree < 0 {
+		return nil, 0, fmt.Errorf("invalid exponent in term %q", term)
+	}
+
+	if degree > MaxParseDegree {
+		return nil, 0, fmt.Errorf("exponent %d in term %q is above %d", degree, term, MaxParseDegree)
+	}
+
+	return coeff, degree, nil
+}
+
+// parseCoefficient parses an unsigned decimal coefficient that must be below the modulus
+func parseCoefficient(s string) (*PrimeField, error) {
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid coefficient %q", s)
+	}
+
+	if value.Cmp(new(big.Int).SetUint64(Modulus)) >= 0 {
+		return nil, fmt.Errorf("coefficient %s is not below the modulus", s)
+	}
+
+	return new(PrimeField).SetBigInt(value), nil
+}
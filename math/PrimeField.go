@@ -146,11 +146,6 @@ func (f *PrimeField) Uint64() uint64 {
 	return f.Element.Uint64()
 }
 
-// toBytes returns the element as a byte array
-func (f *PrimeField) toBytes() []byte {
-	return f.Element.Marshal()
-}
-
 // GetRootOrder returns the order of the prime field
 func (f *PrimeField) GetRootOrder() *PrimeField {
 	return new(PrimeField).SetUint64(ff.Modulus().Uint64() - 1)
@@ -0,0 +1,192 @@
+package math
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PrimeFieldBytes is the size of the canonical encoding of a prime field element
+const PrimeFieldBytes = 8
+
+var (
+	// ErrNonCanonical is returned when an encoded element is not below the modulus
+	ErrNonCanonical = errors.New("non-canonical field element")
+
+	// ErrTrailingBytes is returned when an encoding has bytes left after decoding
+	ErrTrailingBytes = errors.New("trailing bytes after encoding")
+)
+
+// MarshalBinary encodes the element as 8 little-endian bytes of its canonical value
+func (f *PrimeField) MarshalBinary() ([]byte, error) {
+	return f.AppendBinary(make([]byte, 0, PrimeFieldBytes)), nil
+}
+
+// AppendBinary appends the canonical encoding of the element to dst
+func (f *PrimeField) AppendBinary(dst []byte) []byte {
+	return binary.LittleEndian.AppendUint64(dst, f.Uint64())
+}
+
+// UnmarshalBinary decodes an element from exactly 8 little-endian bytes,
+// rejecting values that are not below the modulus
+func (f *PrimeField) UnmarshalBinary(data []byte) error {
+	if len(data) != PrimeFieldBytes {
+		return fmt.Errorf("field element must be %d bytes, got %d", PrimeFieldBytes, len(data))
+	}
+
+	value := binary.LittleEndian.Uint64(data)
+	if value >= Modulus {
+		return fmt.Errorf("%w: %d", ErrNonCanonical, value)
+	}
+
+	f.SetUint64(value)
+	return nil
+}
+
+// MarshalText encodes the element as the decimal representation of its canonical value
+func (f *PrimeField) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, f.Uint64(), 10), nil
+}
+
+// UnmarshalText decodes an element from an unsigned decimal value below the modulus
+func (f *PrimeField) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseUint(string(text), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid field element %q: %w", text, err)
+	}
+
+	if value >= Modulus {
+		return fmt.Errorf("%w: %d", ErrNonCanonical, value)
+	}
+
+	f.SetUint64(value)
+	return nil
+}
+
+// MarshalJSON encodes the element as a decimal string,
+// canonical values do not fit into the integer range of JSON numbers
+func (f *PrimeField) MarshalJSON() ([]byte, error) {
+	text, _ := f.MarshalText()
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes an element from a decimal string
+func (f *PrimeField) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("field element must be a decimal string: %w", err)
+	}
+
+	return f.UnmarshalText([]byte(text))
+}
+
+// AppendElements appends the length-prefixed canonical encoding of elements to dst.
+// The length is a little-endian uint32, nil elements are encoded as zero.
+func AppendElements(dst []byte, elements []*PrimeField) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(elements)))
+
+	for _, element := range elements {
+		if element == nil {
+			element = new(PrimeField)
+		}
+		dst = element.AppendBinary(dst)
+	}
+
+	return dst
+}
+
+// ReadElements decodes length-prefixed elements written by AppendElements
+// and returns the remaining bytes
+func ReadElements(src []byte) ([]*PrimeField, []byte, error) {
+	if len(src) < 4 {
+		return nil, nil, fmt.Errorf("missing length prefix")
+	}
+
+	n := uint64(binary.LittleEndian.Uint32(src))
+	src = src[4:]
+
+	if uint64(len(src)) < n*PrimeFieldBytes {
+		return nil, nil, fmt.Errorf("expected %d elements, got %d bytes", n, len(src))
+	}
+
+	elements := make([]*PrimeField, n)
+	for i := range elements {
+		elements[i] = new(PrimeField)
+		if err := elements[i].UnmarshalBinary(src[:PrimeFieldBytes]); err != nil {
+			return nil, nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		src = src[PrimeFieldBytes:]
+	}
+
+	return elements, src, nil
+}
+
+// MarshalBinary encodes the coefficients as a little-endian uint32 count
+// followed by the canonical encoding of each coefficient
+func (p *Polynom) MarshalBinary() ([]byte, error) {
+	return AppendElements(nil, p.Coefficients), nil
+}
+
+// UnmarshalBinary decodes a polynom written by MarshalBinary
+func (p *Polynom) UnmarshalBinary(data []byte) error {
+	coefficients, rest, err := ReadElements(data)
+	if err != nil {
+		return fmt.Errorf("decode polynom: %w", err)
+	}
+
+	if len(rest) != 0 {
+		return fmt.Errorf("decode polynom: %w", ErrTrailingBytes)
+	}
+
+	p.Coefficients = coefficients
+	return nil
+}
+
+// MarshalText encodes the polynom in the form accepted by ParsePolynom
+func (p *Polynom) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a polynom with ParsePolynom
+func (p *Polynom) UnmarshalText(text []byte) error {
+	res, err := ParsePolynom(string(text))
+	if err != nil {
+		return err
+	}
+
+	p.Coefficients = res.Coefficients
+	return nil
+}
+
+// MarshalJSON encodes the coefficients as an array of decimal strings,
+// unlike the text form it keeps the number of coefficients
+func (p *Polynom) MarshalJSON() ([]byte, error) {
+	coefficients := make([]*PrimeField, len(p.Coefficients))
+	for i, aCoeff := range p.Coefficients {
+		if aCoeff == nil {
+			aCoeff = new(PrimeField)
+		}
+		coefficients[i] = aCoeff
+	}
+
+	return json.Marshal(coefficients)
+}
+
+// UnmarshalJSON decodes a polynom from an array of decimal strings
+func (p *Polynom) UnmarshalJSON(data []byte) error {
+	var coefficients []*PrimeField
+	if err := json.Unmarshal(data, &coefficients); err != nil {
+		return fmt.Errorf("decode polynom: %w", err)
+	}
+
+	for i, aCoeff := range coefficients {
+		if aCoeff == nil {
+			return fmt.Errorf("decode polynom: coefficient %d is null", i)
+		}
+	}
+
+	p.Coefficients = coefficients
+	return nil
+}
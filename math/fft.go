@@ -131,3 +131,33 @@ func (f *PrimeField) FastMultiply(lhs, rhs *Polynom, primitiveRoot *PrimeField,
 
 	return NewPolynom(productCoefficients.Coefficients[:degree+1])
 }
+
+// CosetNTT evaluates a polynomial given by its coefficients on the coset offset·<primitiveRoot>,
+// the number of coefficients must be equal to the order of primitiveRoot
+func (f *PrimeField) CosetNTT(primitiveRoot, offset *PrimeField, values *Polynom) *Polynom {
+	scaled := make([]*PrimeField, values.Len())
+	power := new(PrimeField).SetOne()
+
+	for i, aCoeff := range values.Coefficients {
+		scaled[i] = new(PrimeField).Mul(aCoeff, power)
+		power = power.Mul(power, offset)
+	}
+
+	return f.NTT(primitiveRoot, NewPolynom(scaled))
+}
+
+// CosetINTT interpolates the coefficients of a polynomial from its evaluations on the coset offset·<primitiveRoot>
+func (f *PrimeField) CosetINTT(primitiveRoot, offset *PrimeField, values *Polynom) *Polynom {
+	coefficients := f.INTT(primitiveRoot, values)
+
+	offsetInv := new(PrimeField).Inv(offset)
+	power := new(PrimeField).SetOne()
+
+	output := make([]*PrimeField, coefficients.Len())
+	for i, aCoeff := range coefficients.Coefficients {
+		output[i] = new(PrimeField).Mul(aCoeff, power)
+		power = power.Mul(power, offsetInv)
+	}
+
+	return NewPolynom(output)
+}
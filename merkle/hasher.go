@@ -0,0 +1,73 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// DigestSize is the size of a digest in bytes
+const DigestSize = 32
+
+// Digest is the output of a hash function used for commitments
+type Digest [DigestSize]byte
+
+// Domain tags of the hashes of leaves and of inner nodes, so that no row of elements hashes like a pair of digests.
+// Hashers of bytes prefix their input with the tag, hashers of field elements set it in their capacity.
+const (
+	LeafTag = 0
+	NodeTag = 1
+)
+
+// Hasher is the hash function used to build Merkle trees
+type Hasher interface {
+	// HashElements hashes a row of field elements into a leaf digest in the LeafTag domain
+	HashElements(elements []*math.PrimeField) Digest
+
+	// Merge hashes two child digests into their parent digest in the NodeTag domain
+	Merge(left, right Digest) Digest
+}
+
+// SHA256 hashes the canonical encoding of field elements with SHA-256
+type SHA256 struct{}
+
+// HashElements hashes LeafTag followed by the canonical little-endian encoding of elements
+func (SHA256) HashElements(elements []*math.PrimeField) Digest {
+	data := make([]byte, 1, 1+len(elements)*math.PrimeFieldBytes)
+	data[0] = LeafTag
+	for _, element := range elements {
+		data = element.AppendBinary(data)
+	}
+
+	return sha256.Sum256(data)
+}
+
+// Merge hashes NodeTag followed by the concatenation of two digests
+func (SHA256) Merge(left, right Digest) Digest {
+	data := make([]byte, 0, 1+2*DigestSize)
+	data = append(append(append(data, NodeTag), left[:]...), right[:]...)
+
+	return sha256.Sum256(data)
+}
+
+// ElementsToDigest encodes DigestSize / 8 field elements as a digest in canonical little-endian form,
+// the digest form of hash functions that output field elements
+func ElementsToDigest(elements []*math.PrimeField) Digest {
+	var digest Digest
+	for i, element := range elements {
+		binary.LittleEndian.PutUint64(digest[i*math.PrimeFieldBytes:], element.Uint64())
+	}
+
+	return digest
+}
+
+// DigestToElements decodes a digest into DigestSize / 8 field elements, reducing values above the modulus
+func DigestToElements(digest Digest) []*math.PrimeField {
+	elements := make([]*math.PrimeField, DigestSize/math.PrimeFieldBytes)
+	for i := range elements {
+		elements[i] = math.NewPrimeFieldUint64(binary.LittleEndian.Uint64(digest[i*math.PrimeFieldBytes:]))
+	}
+
+	return elements
+}
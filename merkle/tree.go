@@ -0,0 +1,78 @@
+package merkle
+
+import "fmt"
+
+// Path is the list of sibling digests from a leaf up to the root
+type Path []Digest
+
+// Tree is a binary Merkle tree over a power-of-two number of leaves
+type Tree struct {
+	// nodes stores the tree level by level, nodes[0] are the leaves and nodes[len-1] is the root
+	nodes [][]Digest
+}
+
+// NewTree builds a Merkle tree over the given leaves
+func NewTree(hasher Hasher, leaves []Digest) (*Tree, error) {
+	if len(leaves) == 0 || len(leaves)&(len(leaves)-1) != 0 {
+		return nil, fmt.Errorf("number of leaves must be a power of two, got %d", len(leaves))
+	}
+
+	nodes := [][]Digest{leaves}
+	for level := leaves; len(level) > 1; {
+		parents := make([]Digest, len(level)/2)
+		for i := range parents {
+			parents[i] = hasher.Merge(level[2*i], level[2*i+1])
+		}
+
+		nodes = append(nodes, parents)
+		level = parents
+	}
+
+	return &Tree{nodes: nodes}, nil
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() Digest {
+	return t.nodes[len(t.nodes)-1][0]
+}
+
+// Leaves returns the number of leaves in the tree
+func (t *Tree) Leaves() int {
+	return len(t.nodes[0])
+}
+
+// Depth returns the number of levels above the leaves, i.e. the length of the authentication paths
+func (t *Tree) Depth() int {
+	return len(t.nodes) - 1
+}
+
+// Open returns the authentication path of the leaf at index
+func (t *Tree) Open(index int) Path {
+	path := make(Path, 0, len(t.nodes)-1)
+
+	for _, level := range t.nodes[:len(t.nodes)-1] {
+		path = append(path, level[index^1])
+		index >>= 1
+	}
+
+	return path
+}
+
+// Verify checks that leaf is at index in the tree of the given depth, i.e. with 2^depth leaves, with the given root
+func Verify(hasher Hasher, root Digest, depth, index int, leaf Digest, path Path) bool {
+	if depth < 0 || depth >= 63 || len(path) != depth || index < 0 || index >= 1<<depth {
+		return false
+	}
+
+	node := leaf
+	for _, sibling := range path {
+		if index&1 == 0 {
+			node = hasher.Merge(node, sibling)
+		} else {
+			node = hasher.Merge(sibling, node)
+		}
+		index >>= 1
+	}
+
+	return node == root
+}
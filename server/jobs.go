@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// Status is the state of a proving job
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// errQueueFull is returned when a job is submitted to a full queue or to a closed server
+var errQueueFull = errors.New("proving queue is full")
+
+// job is a receipt waiting to be proven, its state is guarded by mu
+type job struct {
+	id        string
+	receipt   *air.Receipt
+	statement *air.ReceiptAIR
+
+	mu     sync.Mutex
+	status Status
+	proof  []byte
+	err    error
+}
+
+// JobResponse is the response to GET /jobs/{id}, the proof is set once the job is done
+type JobResponse struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	Steps  int    `json:"steps"`
+	Total  string `json:"total"`
+	Proof  []byte `json:"proof,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (j *job) response() JobResponse {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	response := JobResponse{
+		ID:     j.id,
+		Status: j.status,
+		Steps:  j.statement.Steps,
+		Total:  j.statement.Total.String(),
+		Proof:  j.proof,
+	}
+
+	if j.err != nil {
+		response.Error = j.err.Error()
+	}
+
+	return response
+}
+
+func (j *job) setStatus(status Status, proof []byte, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status, j.proof, j.err = status, proof, err
+}
+
+// newID returns a random job ID
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// submit queues a job for the receipt, it does not wait for a free place in the queue
+func (s *Server) submit(receipt *air.Receipt) (*job, error) {
+	j := &job{
+		id:        newID(),
+		receipt:   receipt,
+		statement: receipt.AIR(),
+		status:    StatusQueued,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errQueueFull
+	}
+
+	select {
+	case s.queue <- j:
+	default:
+		return nil, errQueueFull
+	}
+
+	s.jobs[j.id] = j
+	s.metrics.submitted()
+
+	return j, nil
+}
+
+// work proves the queued jobs until the queue is closed, the jobs left after Close fail without being proven
+func (s *Server) work() {
+	defer s.workers.Done()
+
+	for j := range s.queue {
+		if s.ctx.Err() != nil {
+			s.finish(j, StatusFailed, nil, fmt.Errorf("server closed"))
+			continue
+		}
+
+		j.setStatus(StatusRunning, nil, nil)
+		s.metrics.started()
+
+		start := time.Now()
+		proof, err := s.prove(j)
+		s.metrics.proved(time.Since(start))
+
+		if err != nil {
+			s.finish(j, StatusFailed, nil, err)
+			continue
+		}
+
+		s.finish(j, StatusDone, proof, nil)
+	}
+}
+
+// prove proves a job within the job timeout
+func (s *Server) prove(j *job) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.JobTimeout)
+	defer cancel()
+
+	proof, err := stark.ProveContext(ctx, j.statement, j.receipt.Trace(), s.config.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return proof.MarshalBinary()
+}
+
+// finish records the result of a job and drops the oldest finished jobs beyond MaxJobs
+func (s *Server) finish(j *job, status Status, proof []byte, err error) {
+	j.setStatus(status, proof, err)
+	s.metrics.finished(status)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = append(s.finished, j.id)
+	for len(s.jobs) > s.config.MaxJobs && len(s.finished) > 0 {
+		delete(s.jobs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
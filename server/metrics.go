@@ -0,0 +1,196 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proveBuckets are the upper bounds in seconds of the proving duration histogram
+var proveBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metrics are the counters of the service, written in the Prometheus text exposition format
+type metrics struct {
+	mu sync.Mutex
+
+	requests map[[3]string]uint64
+	rejected map[string]uint64
+	finishes map[Status]uint64
+	verifies map[bool]uint64
+
+	submits uint64
+	running int
+
+	// proveCounts[i] is the number of jobs that took at most proveBuckets[i]
+	proveCounts []uint64
+	proveCount  uint64
+	proveSum    float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:    make(map[[3]string]uint64),
+		rejected:    make(map[string]uint64),
+		finishes:    make(map[Status]uint64),
+		verifies:    make(map[bool]uint64),
+		proveCounts: make([]uint64, len(proveBuckets)),
+	}
+}
+
+func (m *metrics) submitted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.submits++
+}
+
+func (m *metrics) reject(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rejected[reason]++
+}
+
+func (m *metrics) started() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running++
+}
+
+func (m *metrics) proved(duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running--
+
+	seconds := duration.Seconds()
+	for i, bound := range proveBuckets {
+		if seconds <= bound {
+			m.proveCounts[i]++
+		}
+	}
+
+	m.proveCount++
+	m.proveSum += seconds
+}
+
+func (m *metrics) finished(status Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.finishes[status]++
+}
+
+func (m *metrics) verified(valid bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.verifies[valid]++
+}
+
+// statusRecorder records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(data)
+}
+
+// instrument counts the requests handled by next by method, route and status code
+func (m *metrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.requests[[3]string{r.Method, route(r.URL.Path), fmt.Sprint(recorder.status)}]++
+	})
+}
+
+// route returns the route of a path, so that the job IDs do not become labels
+func route(path string) string {
+	switch {
+	case path == "/receipts", path == "/verify", path == "/metrics":
+		return path
+	case strings.HasPrefix(path, "/jobs/"):
+		return "/jobs/{id}"
+	default:
+		return "other"
+	}
+}
+
+// write writes the metrics, queued is the number of jobs waiting for a worker
+func (m *metrics) write(w io.Writer, queued int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	header := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	header("simple_air_http_requests_total", "counter", "HTTP requests by method, route and status code.")
+	keys := make([][3]string, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b [3]string) int { return strings.Compare(strings.Join(a[:], " "), strings.Join(b[:], " ")) })
+	for _, key := range keys {
+		fmt.Fprintf(w, "simple_air_http_requests_total{method=%q,route=%q,code=%q} %d\n", key[0], key[1], key[2], m.requests[key])
+	}
+
+	header("simple_air_jobs_submitted_total", "counter", "Proving jobs queued.")
+	fmt.Fprintf(w, "simple_air_jobs_submitted_total %d\n", m.submits)
+
+	header("simple_air_jobs_rejected_total", "counter", "Receipts rejected before queueing by reason.")
+	for _, reason := range []string{"invalid", "too_large", "queue_full"} {
+		fmt.Fprintf(w, "simple_air_jobs_rejected_total{reason=%q} %d\n", reason, m.rejected[reason])
+	}
+
+	header("simple_air_jobs_finished_total", "counter", "Proving jobs finished by status.")
+	for _, status := range []Status{StatusDone, StatusFailed} {
+		fmt.Fprintf(w, "simple_air_jobs_finished_total{status=%q} %d\n", status, m.finishes[status])
+	}
+
+	header("simple_air_jobs_queued", "gauge", "Proving jobs waiting for a worker.")
+	fmt.Fprintf(w, "simple_air_jobs_queued %d\n", queued)
+
+	header("simple_air_jobs_running", "gauge", "Proving jobs being proven.")
+	fmt.Fprintf(w, "simple_air_jobs_running %d\n", m.running)
+
+	header("simple_air_prove_duration_seconds", "histogram", "Time spent proving a job.")
+	for i, bound := range proveBuckets {
+		fmt.Fprintf(w, "simple_air_prove_duration_seconds_bucket{le=\"%g\"} %d\n", bound, m.proveCounts[i])
+	}
+	fmt.Fprintf(w, "simple_air_prove_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.proveCount)
+	fmt.Fprintf(w, "simple_air_prove_duration_seconds_sum %g\n", m.proveSum)
+	fmt.Fprintf(w, "simple_air_prove_duration_seconds_count %d\n", m.proveCount)
+
+	header("simple_air_verifications_total", "counter", "Proofs verified by result.")
+	fmt.Fprintf(w, "simple_air_verifications_total{result=\"valid\"} %d\n", m.verifies[true])
+	fmt.Fprintf(w, "simple_air_verifications_total{result=\"invalid\"} %d\n", m.verifies[false])
+}
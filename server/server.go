@@ -0,0 +1,367 @@
+// Package server is an HTTP service proving receipts in the background.
+//
+// The endpoints are:
+//
+//	POST /receipts  queue a proving job for a CSV (text/csv) or JSON (application/json) receipt, see package ingest
+//	GET  /jobs/{id} return the status of a job and, once it is done, its proof
+//	POST /verify    verify a proof of a receipt total
+//	GET  /metrics   return the metrics of the service in the Prometheus text format
+//
+// Jobs are proven by a fixed number of workers from a bounded queue, a receipt is rejected
+// with 503 Service Unavailable when the queue is full instead of waiting for a worker.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/ingest"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// Config are the limits and the proof parameters of the service
+type Config struct {
+	// Parameters are the proof parameters of every job and of verification
+	Parameters stark.Parameters
+
+	// Workers is the number of jobs proven at the same time
+	Workers int
+
+	// QueueSize is the number of jobs waiting for a worker
+	QueueSize int
+
+	// MaxJobs is the number of jobs kept for GET /jobs/{id}, the oldest finished jobs are dropped first
+	MaxJobs int
+
+	// MaxBodyBytes is the size limit of request bodies
+	MaxBodyBytes int64
+
+	// MaxSteps is the largest trace length of a receipt, i.e. its number of lines plus one rounded up to a power of two
+	MaxSteps int
+
+	// JobTimeout is the time a job may take to prove
+	JobTimeout time.Duration
+
+	// RequestTimeout is the time a request may take, including the verification of a proof
+	RequestTimeout time.Duration
+}
+
+// DefaultConfig returns a configuration for a local service
+func DefaultConfig() Config {
+	return Config{
+		Parameters:     stark.DefaultParameters(),
+		Workers:        2,
+		QueueSize:      16,
+		MaxJobs:        1024,
+		MaxBodyBytes:   1 << 20,
+		MaxSteps:       1 << 16,
+		JobTimeout:     time.Minute,
+		RequestTimeout: 10 * time.Second,
+	}
+}
+
+// Validate checks that the configuration is usable
+func (c Config) Validate() error {
+	if err := c.Parameters.Validate(); err != nil {
+		return err
+	}
+
+	if c.Workers < 1 || c.QueueSize < 1 || c.MaxJobs < 1 {
+		return fmt.Errorf("workers, queue size and max jobs must be positive")
+	}
+
+	if c.MaxBodyBytes < 1 || c.MaxSteps < 2 {
+		return fmt.Errorf("max body bytes must be positive and max steps at least 2")
+	}
+
+	if c.JobTimeout <= 0 || c.RequestTimeout <= 0 {
+		return fmt.Errorf("timeouts must be positive")
+	}
+
+	return nil
+}
+
+// Server is the proving service, it serves the endpoints of the package until it is closed
+type Server struct {
+	config  Config
+	handler http.Handler
+	metrics *metrics
+
+	// ctx is cancelled on Close, which cancels the running jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan *job
+	jobs   map[string]*job
+
+	// finished are the IDs of finished jobs in the order they finished
+	finished []string
+
+	workers sync.WaitGroup
+}
+
+// New starts the workers of a service
+func New(config Config) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		config:  config,
+		metrics: newMetrics(),
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   make(chan *job, config.QueueSize),
+		jobs:    make(map[string]*job),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /receipts", s.handleReceipt)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+	mux.HandleFunc("POST /verify", s.handleVerify)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+
+	s.handler = s.metrics.instrument(http.TimeoutHandler(mux, config.RequestTimeout, "request timed out\n"))
+
+	for range config.Workers {
+		s.workers.Add(1)
+		go s.work()
+	}
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Close stops accepting jobs, cancels the running ones and waits for the workers to return.
+// Queued jobs are marked as failed.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	s.cancel()
+	s.workers.Wait()
+}
+
+// errorResponse is the body of every error response
+type errorResponse struct {
+	Error string `json:"error"`
+
+	// Lines are the line errors of an invalid receipt
+	Lines []lineError `json:"lines,omitempty"`
+}
+
+type lineError struct {
+	Line  int    `json:"line"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	body := errorResponse{Error: err.Error()}
+
+	var errs ingest.Errors
+	if errors.As(err, &errs) {
+		body.Error = "invalid receipt"
+		for _, e := range errs {
+			body.Lines = append(body.Lines, lineError{Line: e.Line, Field: e.Field, Error: e.Err.Error()})
+		}
+	}
+
+	writeJSON(w, status, body)
+}
+
+// bodyError returns the status of an error reading a request body
+func bodyError(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
+
+// receiptResponse is the response to a queued receipt
+type receiptResponse struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	Steps  int    `json:"steps"`
+	Total  string `json:"total"`
+}
+
+func (s *Server) handleReceipt(w http.ResponseWriter, r *http.Request) {
+	scale := ingest.DefaultScale
+	if text := r.URL.Query().Get("scale"); text != "" {
+		var err error
+		if scale, err = strconv.Atoi(text); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid scale %q", text))
+			return
+		}
+	}
+
+	read := ingest.ReadJSON
+	switch contentType(r) {
+	case "application/json":
+	case "text/csv":
+		read = ingest.ReadCSV
+	default:
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be text/csv or application/json"))
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+
+	parsed, err := read(body, scale)
+	if err != nil {
+		var errs ingest.Errors
+		switch status := bodyError(err); {
+		case status == http.StatusRequestEntityTooLarge:
+			writeError(w, status, err)
+		case errors.As(err, &errs):
+			writeError(w, http.StatusUnprocessableEntity, err)
+		default:
+			writeError(w, status, err)
+		}
+
+		s.metrics.reject("invalid")
+		return
+	}
+
+	receipt := parsed.Compute()
+	if len(receipt.First) > s.config.MaxSteps {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("receipt has %d steps, at most %d are proven", len(receipt.First), s.config.MaxSteps))
+		s.metrics.reject("too_large")
+		return
+	}
+
+	j, err := s.submit(receipt)
+	if err != nil {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, err)
+		s.metrics.reject("queue_full")
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, receiptResponse{
+		ID:     j.id,
+		Status: StatusQueued,
+		Steps:  j.statement.Steps,
+		Total:  j.statement.Total.String(),
+	})
+}
+
+// contentType returns the media type of the request without parameters
+func contentType(r *http.Request) string {
+	value := r.Header.Get("Content-Type")
+	for i, c := range value {
+		if c == ';' || c == ' ' {
+			return value[:i]
+		}
+	}
+
+	return value
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	j, ok := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", r.PathValue("id")))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j.response())
+}
+
+// VerifyRequest is the body of POST /verify, the proof is the binary proof encoded as base64 in JSON
+type VerifyRequest struct {
+	Proof []byte `json:"proof"`
+	Total string `json:"total"`
+
+	// Steps is the trace length of the receipt, the one of the proof if 0
+	Steps int `json:"steps,omitempty"`
+}
+
+// VerifyResponse is the response to POST /verify
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var request VerifyRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, bodyError(err), fmt.Errorf("decode request: %w", err))
+		return
+	}
+
+	total := new(math.PrimeField)
+	if err := total.UnmarshalText([]byte(request.Total)); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("total: %w", err))
+		return
+	}
+
+	proof := new(stark.Proof)
+	if err := proof.UnmarshalBinary(request.Proof); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("proof: %w", err))
+		return
+	}
+
+	statement := &air.ReceiptAIR{Steps: request.Steps, Total: total}
+	if statement.Steps == 0 {
+		statement.Steps = int(proof.TraceLength)
+	}
+
+	if statement.Steps > s.config.MaxSteps {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("proof has %d steps, at most %d are verified", statement.Steps, s.config.MaxSteps))
+		return
+	}
+
+	response := VerifyResponse{Valid: true}
+	if err := stark.Verify(statement, proof, s.config.Parameters); err != nil {
+		response = VerifyResponse{Error: err.Error()}
+	}
+
+	s.metrics.verified(response.Valid)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	queued := len(s.queue)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.write(w, queued)
+}
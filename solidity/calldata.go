@@ -0,0 +1,105 @@
+package solidity
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/KyrylR/simple-air/hash/keccak"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// Word is an EVM word
+type Word [32]byte
+
+// VerifySignature is the signature of the verify function of generated contracts
+const VerifySignature = "verify(uint256[],uint256[])"
+
+func elementWord(element *math.PrimeField) Word {
+	var word Word
+	binary.BigEndian.PutUint64(word[24:], element.Uint64())
+
+	return word
+}
+
+// EncodeProof returns the words of the proof in the order of NewLayout, digests are words as they are
+// and field elements and the proof-of-work nonce are big-endian integers
+func EncodeProof(shape *stark.Shape, proof *stark.Proof) ([]Word, error) {
+	if proof.Parameters != shape.Parameters {
+		return nil, fmt.Errorf("proof parameters %+v do not match %+v", proof.Parameters, shape.Parameters)
+	}
+
+	if int(proof.TraceLength) != shape.TraceLength || int(proof.TraceWidth) != shape.TraceWidth || len(proof.AuxiliaryRoots) != 0 {
+		return nil, fmt.Errorf("proof is not of the shape of the contract")
+	}
+
+	layout := NewLayout(shape)
+	words := make([]Word, 0, layout.Length)
+
+	digests := func(digests ...merkle.Digest) {
+		for _, digest := range digests {
+			words = append(words, Word(digest))
+		}
+	}
+
+	elements := func(elements ...*math.PrimeField) {
+		for _, element := range elements {
+			words = append(words, elementWord(element))
+		}
+	}
+
+	digests(proof.TraceRoot, proof.CompositionRoot)
+	elements(proof.OOD.Current...)
+	elements(proof.OOD.Next...)
+	elements(proof.OOD.Composition...)
+	digests(proof.FRI.Roots...)
+	elements(proof.FRI.Remainder...)
+	elements(math.NewPrimeFieldUint64(0))
+	binary.BigEndian.PutUint64(words[len(words)-1][24:], proof.PowNonce)
+
+	for _, query := range proof.Queries {
+		for _, opening := range []stark.Opening{query.Trace, query.Composition} {
+			elements(opening.Values...)
+			elements(opening.Salt...)
+			digests(opening.Path...)
+		}
+
+		for _, opening := range query.FRI {
+			elements(opening.Values...)
+			digests(opening.Path...)
+		}
+	}
+
+	if len(words) != layout.Length {
+		return nil, fmt.Errorf("proof has %d words, expected %d", len(words), layout.Length)
+	}
+
+	return words, nil
+}
+
+// Calldata returns the ABI encoded call of verify(proof, inputs)
+func Calldata(proof []Word, inputs []*math.PrimeField) []byte {
+	selector := keccak.Sum256([]byte(VerifySignature))
+
+	uint256 := func(value int) Word {
+		var word Word
+		binary.BigEndian.PutUint64(word[24:], uint64(value))
+
+		return word
+	}
+
+	words := []Word{uint256(2 * 32), uint256((3 + len(proof)) * 32), uint256(len(proof))}
+	words = append(words, proof...)
+	words = append(words, uint256(len(inputs)))
+	for _, input := range inputs {
+		words = append(words, elementWord(input))
+	}
+
+	out := append([]byte{}, selector[:4]...)
+	for _, word := range words {
+		out = append(out, word[:]...)
+	}
+
+	return out
+}
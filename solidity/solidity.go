@@ -0,0 +1,245 @@
+// Package solidity generates Solidity contracts verifying STARK proofs of an AIR on the EVM.
+//
+// A contract is specialized to one AIR with fixed parameters: the trace shape, the domains and the layout
+// of the proof are constants, and the public inputs of the statement are passed next to the proof.
+// Proofs must use stark.HashKeccak256, which hashes field elements as EVM words, and are passed
+// as the flat list of words returned by EncodeProof. Field elements live in uint256 and are reduced
+// with addmod and mulmod modulo the Goldilocks prime.
+//
+// The generated verifier covers AIRs without auxiliary segments and periodic columns.
+package solidity
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"text/template"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+//go:embed verifier.sol.tmpl
+var verifierTemplate string
+
+var tmpl = template.Must(template.New("verifier").Funcs(template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+}).Parse(verifierTemplate))
+
+// Assertion is an assertion of the AIR, its value is either a constant or a public input
+type Assertion struct {
+	Column int
+	Step   int
+
+	// Value is the constant value, nil if the value is the public input Input
+	Value *math.PrimeField
+	Input int
+}
+
+// Spec describes the AIR a contract verifies
+type Spec struct {
+	// Name is the name of the contract
+	Name string
+
+	// Description is the statement proven, in the doc comment of the contract
+	Description string
+
+	Shape *stark.Shape
+
+	// Inputs are the names of the public inputs, in the order the contract takes them
+	Inputs []string
+
+	// Assertions are the assertions of the AIR in the order of air.AIR.Assertions
+	Assertions []Assertion
+
+	// NumTransitions is the number of transition constraints
+	NumTransitions int
+
+	// Transition are Solidity statements setting t[i] to the transition constraints evaluated
+	// on the uint256[] memory rows current and next, with the field helpers _add, _sub and _mul
+	Transition string
+}
+
+// NewSpec collects the shape of the AIR with the parameters, which must use stark.HashKeccak256
+func NewSpec(a air.AIR, params stark.Parameters) (*Spec, error) {
+	if params.Hash != stark.HashKeccak256 {
+		return nil, fmt.Errorf("contracts verify proofs hashed with %v, got %v", stark.HashKeccak256, params.Hash)
+	}
+
+	if _, ok := a.(air.AuxiliaryAIR); ok {
+		return nil, fmt.Errorf("contracts do not support auxiliary segments")
+	}
+
+	if len(air.Periodic(a)) != 0 {
+		return nil, fmt.Errorf("contracts do not support periodic columns")
+	}
+
+	if len(air.PublicInputs(a)) != 0 {
+		return nil, fmt.Errorf("contracts do not support public inputs outside of the assertions")
+	}
+
+	shape, err := stark.NewShape(a, params)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{Shape: shape, NumTransitions: a.NumTransitionConstraints()}
+	for _, assertion := range a.Assertions() {
+		spec.Assertions = append(spec.Assertions, Assertion{Column: assertion.Column, Step: assertion.Step, Value: assertion.Value})
+	}
+
+	return spec, nil
+}
+
+// ReceiptSpec returns the spec of the verifier of receipts of the given trace length,
+// whose public input is the total
+func ReceiptSpec(steps int, params stark.Parameters) (*Spec, error) {
+	spec, err := NewSpec(&air.ReceiptAIR{Steps: steps, Total: new(math.PrimeField).SetZero()}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Name = "ReceiptVerifier"
+	spec.Description = fmt.Sprintf("receipts of %d steps, i.e. a trace (price, running sum) starting at zero and ending with the total", steps)
+	spec.Inputs = []string{"total"}
+	spec.Transition = "t[0] = _sub(next[1], _add(current[0], current[1]));"
+
+	// Both columns end with the total
+	for i := range spec.Assertions {
+		if spec.Assertions[i].Step == steps-1 {
+			spec.Assertions[i].Value = nil
+			spec.Assertions[i].Input = 0
+		}
+	}
+
+	return spec, nil
+}
+
+// Layout is the position of every part of a proof in the words returned by EncodeProof
+type Layout struct {
+	TraceRoot       int
+	CompositionRoot int
+	OODCurrent      int
+	OODNext         int
+	OODComposition  int
+	FRIRoots        int
+	Remainder       int
+	PowNonce        int
+
+	// Queries is the position of the first query, every query takes QuerySize words: the opened trace row
+	// with its salt and path, the opened composition row with its salt and path, then the pair and the path of every FRI layer
+	Queries   int
+	QuerySize int
+
+	// Depth is the depth of the trace and composition trees
+	Depth int
+
+	// Length is the number of words of a proof
+	Length int
+}
+
+// NewLayout returns the layout of proofs of the shape
+func NewLayout(shape *stark.Shape) *Layout {
+	width := shape.Width()
+	depth := bits.Len(uint(shape.DomainSize)) - 1
+
+	l := &Layout{TraceRoot: 0, CompositionRoot: 1, OODCurrent: 2, Depth: depth}
+	l.OODNext = l.OODCurrent + width
+	l.OODComposition = l.OODNext + width
+	l.FRIRoots = l.OODComposition + shape.CompositionWidth
+	l.Remainder = l.FRIRoots + shape.FRIFolds
+	l.PowNonce = l.Remainder + shape.RemainderSize
+	l.Queries = l.PowNonce + 1
+
+	l.QuerySize = width + shape.CompositionWidth + 2*(shape.SaltElements+depth)
+	for layer := range shape.FRIFolds {
+		l.QuerySize += 2 + depth - layer - 1
+	}
+
+	l.Length = l.Queries + int(shape.Parameters.NumQueries)*l.QuerySize
+
+	return l
+}
+
+// templateData are the values of the verifier template, field elements are decimal strings
+type templateData struct {
+	*Spec
+
+	Layout *Layout
+	Width  int
+
+	LogDomainSize int
+	OffsetInverse string
+	TwoInverse    string
+	LastStep      string
+	GrindingBits  uint32
+	NumQueries    uint32
+	Assertions    []templateAssertion
+}
+
+type templateAssertion struct {
+	Index  int
+	Column int
+	Step   int
+
+	// SeedAt is the position of the assertion in the seed of the transcript
+	SeedAt int
+
+	// Point is ω^Step and Value is a constant or inputs[i]
+	Point string
+	Value string
+}
+
+// Generate writes the verifier contract of the spec
+func Generate(w io.Writer, spec *Spec) error {
+	if spec.Name == "" || spec.Transition == "" {
+		return fmt.Errorf("spec needs a contract name and transition constraints")
+	}
+
+	shape := spec.Shape
+	traceElement := func(step int) string {
+		return new(math.PrimeField).Exp(shape.TraceRoot, big.NewInt(int64(step))).String()
+	}
+
+	data := templateData{
+		Spec:          spec,
+		Layout:        NewLayout(shape),
+		Width:         shape.Width(),
+		LogDomainSize: bits.Len(uint(shape.DomainSize)) - 1,
+		OffsetInverse: new(math.PrimeField).Inv(shape.Offset).String(),
+		TwoInverse:    new(math.PrimeField).Inv(math.NewPrimeField(2)).String(),
+		LastStep:      traceElement(shape.TraceLength - 1),
+		GrindingBits:  shape.Parameters.GrindingBits,
+		NumQueries:    shape.Parameters.NumQueries,
+	}
+
+	for k, assertion := range spec.Assertions {
+		value := fmt.Sprintf("inputs[%d]", assertion.Input)
+		if assertion.Value != nil {
+			value = assertion.Value.String()
+		} else if assertion.Input < 0 || assertion.Input >= len(spec.Inputs) {
+			return fmt.Errorf("assertion %d refers to input %d of %d", k, assertion.Input, len(spec.Inputs))
+		}
+
+		data.Assertions = append(data.Assertions, templateAssertion{
+			Index:  k,
+			Column: assertion.Column,
+			Step:   assertion.Step,
+			SeedAt: 3 + 3*k,
+			Point:  traceElement(assertion.Step),
+			Value:  value,
+		})
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return err
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
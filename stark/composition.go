@@ -0,0 +1,67 @@
+package stark
+
+import (
+	"math/big"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// compositionCoefficients are the random coefficients combining all constraints into one polynomial
+type compositionCoefficients struct {
+	transition []*math.PrimeField
+	boundary   []*math.PrimeField
+}
+
+func drawCompositionCoefficients(t *transcript, c *constraints) *compositionCoefficients {
+	return &compositionCoefficients{
+		transition: t.drawElements(c.numTransitions()),
+		boundary:   t.drawElements(len(c.assertions)),
+	}
+}
+
+// evaluateComposition evaluates the composition polynomial at x from the values of all trace segments at x and x·ω:
+//
+//	C(x) = Σ α_i·t_i(x) / Z_T(x) + Σ β_k·(T_{c_k}(x) - v_k) / (x - ω^{s_k})
+//
+// where Z_T(x) = (x^n - 1) / (x - ω^(n-1)) vanishes on every step except the last one
+func evaluateComposition(c *constraints, d *domain, coefficients *compositionCoefficients, x *math.PrimeField, current, next []*math.PrimeField) *math.PrimeField {
+	one := new(math.PrimeField).SetOne()
+
+	xn := new(math.PrimeField).Exp(x, big.NewInt(int64(d.traceLength)))
+	zerofier := new(math.PrimeField).Div(
+		new(math.PrimeField).Sub(xn, one),
+		new(math.PrimeField).Sub(x, d.traceElement(d.traceLength-1)),
+	)
+	zerofierInv := new(math.PrimeField).Inv(zerofier)
+
+	result := new(math.PrimeField).SetZero()
+
+	for i, value := range c.evaluateTransition(d, x, current, next) {
+		term := new(math.PrimeField).Mul(coefficients.transition[i], value)
+		result = result.Add(result, term.Mul(term, zerofierInv))
+	}
+
+	for k, assertion := range c.assertions {
+		numerator := new(math.PrimeField).Sub(current[assertion.Column], assertion.Value)
+		denominator := new(math.PrimeField).Sub(x, d.traceElement(assertion.Step))
+
+		term := new(math.PrimeField).Mul(coefficients.boundary[k], numerator)
+		result = result.Add(result, term.Div(term, denominator))
+	}
+
+	return result
+}
+
+// combineCompositionColumns returns C(z) = Σ z^(j·n)·C_j(z) from the evaluations of the composition columns
+func combineCompositionColumns(d *domain, z *math.PrimeField, columns []*math.PrimeField) *math.PrimeField {
+	zn := new(math.PrimeField).Exp(z, big.NewInt(int64(d.traceLength)))
+	power := new(math.PrimeField).SetOne()
+
+	result := new(math.PrimeField).SetZero()
+	for _, value := range columns {
+		result = result.Add(result, new(math.PrimeField).Mul(power, value))
+		power = power.Mul(power, zn)
+	}
+
+	return result
+}
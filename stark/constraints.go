@@ -0,0 +1,115 @@
+package stark
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+)
+
+// constraints are the constraints of an AIR over rows holding the main columns followed by the columns of every auxiliary segment
+type constraints struct {
+	air air.AIR
+
+	// periodic are the periodic columns of the AIR and the polynomials interpolating them over their periods
+	periodic      []air.PeriodicColumn
+	periodicPolys []*math.Polynom
+
+	// segments are the auxiliary segments of the AIR with the challenges each of them is built with
+	segments   []air.AuxiliarySegment
+	challenges [][]*math.PrimeField
+
+	// assertions are the main and auxiliary assertions with columns indexed in the full row
+	assertions []air.Assertion
+}
+
+// auxiliary returns the auxiliary segments of the AIR, nil if it has none
+func auxiliary(a air.AIR) []air.AuxiliarySegment {
+	if aux, ok := a.(air.AuxiliaryAIR); ok {
+		return aux.AuxiliarySegments()
+	}
+
+	return nil
+}
+
+// newConstraints collects the constraints of the AIR with the challenges of each of its auxiliary segments
+func newConstraints(a air.AIR, challenges [][]*math.PrimeField) (*constraints, error) {
+	c := &constraints{
+		air:        a,
+		segments:   auxiliary(a),
+		challenges: challenges,
+		assertions: a.Assertions(),
+		periodic:   air.Periodic(a),
+	}
+
+	for _, column := range c.periodic {
+		c.periodicPolys = append(c.periodicPolys, column.Polynomial())
+	}
+
+	offset := a.TraceWidth()
+	for k, segment := range c.segments {
+		for _, assertion := range segment.Assertions(challenges[k]) {
+			if assertion.Step < 0 || assertion.Step >= a.TraceLength() || assertion.Column < 0 || assertion.Column >= segment.Width() {
+				return nil, fmt.Errorf("auxiliary segment %d assertion at step %d column %d is outside of the trace",
+					k+1, assertion.Step, assertion.Column)
+			}
+
+			assertion.Column += offset
+			c.assertions = append(c.assertions, assertion)
+		}
+
+		offset += segment.Width()
+	}
+
+	return c, nil
+}
+
+// numTransitions returns the number of values returned by evaluateTransition
+func (c *constraints) numTransitions() int {
+	n := c.air.NumTransitionConstraints()
+	for _, segment := range c.segments {
+		n += segment.NumConstraints()
+	}
+
+	return n
+}
+
+// evaluateTransition evaluates the main and auxiliary transition constraints on the full rows at x and x·ω
+func (c *constraints) evaluateTransition(d *domain, x *math.PrimeField, current, next []*math.PrimeField) []*math.PrimeField {
+	width := c.air.TraceWidth()
+
+	res := c.air.EvaluateTransition(c.withPeriodic(d, x, current[:width]), c.withPeriodic(d, new(math.PrimeField).Mul(x, d.traceRoot), next[:width]))
+	if len(c.segments) == 0 {
+		return res
+	}
+
+	currentRows := [][]*math.PrimeField{current[:width]}
+	nextRows := [][]*math.PrimeField{next[:width]}
+
+	offset := width
+	for k, segment := range c.segments {
+		end := offset + segment.Width()
+		currentRows = append(currentRows, current[offset:end])
+		nextRows = append(nextRows, next[offset:end])
+
+		res = append(res, segment.Evaluate(currentRows, nextRows, c.challenges[k])...)
+		offset = end
+	}
+
+	return res
+}
+
+// withPeriodic returns the main row at x followed by the values of the periodic columns at x
+func (c *constraints) withPeriodic(d *domain, x *math.PrimeField, row []*math.PrimeField) []*math.PrimeField {
+	if len(c.periodic) == 0 {
+		return row
+	}
+
+	extended := make([]*math.PrimeField, 0, len(row)+len(c.periodic))
+	extended = append(extended, row...)
+	for i, column := range c.periodic {
+		extended = append(extended, column.EvaluateAt(c.periodicPolys[i], x, d.traceLength))
+	}
+
+	return extended
+}
@@ -0,0 +1,45 @@
+package stark
+
+import "github.com/KyrylR/simple-air/math"
+
+// deepCoefficients are the random coefficients of the DEEP composition polynomial
+type deepCoefficients struct {
+	current     []*math.PrimeField
+	next        []*math.PrimeField
+	composition []*math.PrimeField
+}
+
+func drawDeepCoefficients(t *transcript, traceWidth, compositionWidth int) *deepCoefficients {
+	return &deepCoefficients{
+		current:     t.drawElements(traceWidth),
+		next:        t.drawElements(traceWidth),
+		composition: t.drawElements(compositionWidth),
+	}
+}
+
+// evaluateDeep evaluates the DEEP composition polynomial at x from the trace and composition values at x:
+//
+//	D(x) = Σ γ_j·(T_j(x) - T_j(z)) / (x - z) + Σ γ'_j·(T_j(x) - T_j(z·ω)) / (x - z·ω) + Σ δ_k·(C_k(x) - C_k(z)) / (x - z)
+//
+// D is a polynomial of degree below the trace length if and only if the out-of-domain evaluations are correct
+func evaluateDeep(coefficients *deepCoefficients, ood *OODFrame, z, zNext, x *math.PrimeField, trace, composition []*math.PrimeField) *math.PrimeField {
+	currentInv := new(math.PrimeField).Inv(new(math.PrimeField).Sub(x, z))
+	nextInv := new(math.PrimeField).Inv(new(math.PrimeField).Sub(x, zNext))
+
+	sumCurrent := new(math.PrimeField).SetZero()
+	sumNext := new(math.PrimeField).SetZero()
+
+	for j, value := range trace {
+		sumCurrent = sumCurrent.Add(sumCurrent, new(math.PrimeField).Mul(coefficients.current[j], new(math.PrimeField).Sub(value, ood.Current[j])))
+		sumNext = sumNext.Add(sumNext, new(math.PrimeField).Mul(coefficients.next[j], new(math.PrimeField).Sub(value, ood.Next[j])))
+	}
+
+	for k, value := range composition {
+		sumCurrent = sumCurrent.Add(sumCurrent, new(math.PrimeField).Mul(coefficients.composition[k], new(math.PrimeField).Sub(value, ood.Composition[k])))
+	}
+
+	return new(math.PrimeField).Add(
+		new(math.PrimeField).Mul(sumCurrent, currentInv),
+		new(math.PrimeField).Mul(sumNext, nextInv),
+	)
+}
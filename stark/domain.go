@@ -0,0 +1,98 @@
+package stark
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+// domain describes the trace domain <ω> of size n
+// and the evaluation domain g·<ω_N> of size N = n·blowup, where g is the field generator
+type domain struct {
+	traceLength int
+	size        int
+	blowup      int
+
+	// traceRoot is ω, a primitive root of unity of order traceLength
+	traceRoot *math.PrimeField
+
+	// root is ω_N, a primitive root of unity of order size, ω_N^blowup = ω
+	root *math.PrimeField
+
+	// offset is the coset offset g
+	offset *math.PrimeField
+
+	// points are the elements of the evaluation domain in natural order
+	points []*math.PrimeField
+}
+
+// depth returns the depth of a Merkle tree over the evaluation domain, log2(N)
+func (d *domain) depth() int {
+	return bits.Len(uint(d.size)) - 1
+}
+
+func newDomain(traceLength, blowup int) *domain {
+	size := traceLength * blowup
+	pf := new(math.PrimeField)
+
+	root := pf.GetRootOfUnity(uint64(size))
+	offset := math.NewPrimeFieldUint64(math.Generator)
+
+	points := make([]*math.PrimeField, size)
+	points[0] = offset.Copy()
+	for i := 1; i < size; i++ {
+		points[i] = new(math.PrimeField).Mul(points[i-1], root)
+	}
+
+	return &domain{
+		traceLength: traceLength,
+		size:        size,
+		blowup:      blowup,
+		traceRoot:   new(math.PrimeField).Exp(root, big.NewInt(int64(blowup))),
+		root:        root,
+		offset:      offset,
+		points:      points,
+	}
+}
+
+// interpolate returns the coefficients of the polynomial taking the values on the trace domain
+func (d *domain) interpolate(values []*math.PrimeField) *math.Polynom {
+	return new(math.PrimeField).INTT(d.traceRoot, math.NewPolynom(values))
+}
+
+// extend evaluates a polynomial of degree below size on the evaluation domain
+func (d *domain) extend(poly *math.Polynom) []*math.PrimeField {
+	coefficients := make([]*math.PrimeField, d.size)
+	for i := range coefficients {
+		if i < poly.Len() {
+			coefficients[i] = poly.At(i)
+		} else {
+			coefficients[i] = new(math.PrimeField).SetZero()
+		}
+	}
+
+	return new(math.PrimeField).CosetNTT(d.root, d.offset, math.NewPolynom(coefficients)).Coefficients
+}
+
+// interpolateExtended returns the coefficients of the polynomial taking the values on the evaluation domain
+func (d *domain) interpolateExtended(values []*math.PrimeField) *math.Polynom {
+	return new(math.PrimeField).CosetINTT(d.root, d.offset, math.NewPolynom(values))
+}
+
+// traceElement returns ω^step
+func (d *domain) traceElement(step int) *math.PrimeField {
+	return new(math.PrimeField).Exp(d.traceRoot, big.NewInt(int64(step)))
+}
+
+// inDomains reports whether x belongs to the trace domain or the evaluation domain
+func (d *domain) inDomains(x *math.PrimeField) bool {
+	one := new(math.PrimeField).SetOne()
+
+	if new(math.PrimeField).Exp(x, big.NewInt(int64(d.traceLength))).Equals(one) {
+		return true
+	}
+
+	shifted := new(math.PrimeField).Div(x, d.offset)
+	return new(math.PrimeField).Exp(shifted, big.NewInt(int64(d.size))).Equals(one)
+}
@@ -0,0 +1,339 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// ProofVersion is the version of the binary proof format
+const ProofVersion uint16 = 4
+
+// proofMagic starts every encoded proof
+var proofMagic = [4]byte{'S', 'A', 'I', 'R'}
+
+// headerSize is the size of the magic, the version and the parameter fingerprint
+const headerSize = len(proofMagic) + 2 + 8
+
+// ErrInvalidProof is returned when a proof cannot be decoded
+var ErrInvalidProof = errors.New("invalid proof encoding")
+
+// Section names of the binary proof format, in encoding order
+const (
+	SectionHeader      = "header"
+	SectionParameters  = "parameters"
+	SectionContext     = "context"
+	SectionTrace       = "trace commitment"
+	SectionAuxiliary   = "auxiliary commitments"
+	SectionComposition = "composition commitment"
+	SectionOOD         = "ood evaluations"
+	SectionFRI         = "fri commitments"
+	SectionPoW         = "proof of work"
+	SectionQueries     = "queries"
+)
+
+// SectionSize is the number of bytes a section takes in the encoded proof,
+// including its length prefix
+type SectionSize struct {
+	Name  string
+	Bytes int
+}
+
+// sectionEncoders returns the encoders of all sections after the header in order
+func (p *Proof) sectionEncoders() []struct {
+	name   string
+	encode func() []byte
+} {
+	return []struct {
+		name   string
+		encode func() []byte
+	}{
+		{SectionParameters, func() []byte { data, _ := p.Parameters.MarshalBinary(); return data }},
+		{SectionContext, func() []byte {
+			out := binary.LittleEndian.AppendUint32(nil, p.TraceLength)
+			return binary.LittleEndian.AppendUint32(out, p.TraceWidth)
+		}},
+		{SectionTrace, func() []byte { return p.TraceRoot[:] }},
+		{SectionAuxiliary, func() []byte { return appendDigests(nil, p.AuxiliaryRoots) }},
+		{SectionComposition, func() []byte { return p.CompositionRoot[:] }},
+		{SectionOOD, func() []byte {
+			out := math.AppendElements(nil, p.OOD.Current)
+			out = math.AppendElements(out, p.OOD.Next)
+			return math.AppendElements(out, p.OOD.Composition)
+		}},
+		{SectionFRI, func() []byte {
+			out := appendDigests(nil, p.FRI.Roots)
+			return math.AppendElements(out, p.FRI.Remainder)
+		}},
+		{SectionPoW, func() []byte { return binary.LittleEndian.AppendUint64(nil, p.PowNonce) }},
+		{SectionQueries, func() []byte {
+			out := binary.LittleEndian.AppendUint32(nil, uint32(len(p.Queries)))
+			for _, query := range p.Queries {
+				out = binary.LittleEndian.AppendUint32(out, query.Index)
+				out = appendOpening(out, query.Trace)
+				out = binary.LittleEndian.AppendUint32(out, uint32(len(query.Auxiliary)))
+				for _, opening := range query.Auxiliary {
+					out = appendOpening(out, opening)
+				}
+				out = appendOpening(out, query.Composition)
+				out = binary.LittleEndian.AppendUint32(out, uint32(len(query.FRI)))
+				for _, opening := range query.FRI {
+					out = appendOpening(out, opening)
+				}
+			}
+			return out
+		}},
+	}
+}
+
+// MarshalBinary encodes the proof.
+//
+// The encoding starts with the magic "SAIR", the little-endian uint16 format version
+// and the parameter fingerprint, followed by the sections in order,
+// each prefixed with its little-endian uint32 length.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	fingerprint := p.Parameters.Fingerprint()
+
+	out := append([]byte{}, proofMagic[:]...)
+	out = binary.LittleEndian.AppendUint16(out, ProofVersion)
+	out = append(out, fingerprint[:]...)
+
+	for _, section := range p.sectionEncoders() {
+		body := section.encode()
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+		out = append(out, body...)
+	}
+
+	return out, nil
+}
+
+// Sizes returns the number of bytes each section takes in the encoded proof
+func (p *Proof) Sizes() []SectionSize {
+	sizes := []SectionSize{{Name: SectionHeader, Bytes: headerSize}}
+
+	for _, section := range p.sectionEncoders() {
+		sizes = append(sizes, SectionSize{Name: section.name, Bytes: 4 + len(section.encode())})
+	}
+
+	return sizes
+}
+
+// UnmarshalBinary decodes a proof written by MarshalBinary.
+// Unknown versions, fingerprint mismatches, non-canonical elements
+// and trailing bytes in any section or after the proof are rejected.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || !bytes.Equal(data[:len(proofMagic)], proofMagic[:]) {
+		return fmt.Errorf("%w: missing magic", ErrInvalidProof)
+	}
+
+	if version := binary.LittleEndian.Uint16(data[len(proofMagic):]); version != ProofVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidProof, version)
+	}
+
+	fingerprint := [8]byte(data[len(proofMagic)+2 : headerSize])
+	data = data[headerSize:]
+
+	var decoded Proof
+	decoders := []struct {
+		name   string
+		decode func(r *reader)
+	}{
+		{SectionParameters, func(r *reader) {
+			decoded.Parameters = Parameters{
+				BlowupFactor:       r.uint32(),
+				NumQueries:         r.uint32(),
+				GrindingBits:       r.uint32(),
+				MaxRemainderDegree: r.uint32(),
+				Hash:               HashFunction(r.byte()),
+			}
+
+			switch flag := r.byte(); flag {
+			case 0, 1:
+				decoded.Parameters.ZeroKnowledge = flag == 1
+			default:
+				r.err = fmt.Errorf("invalid zero-knowledge flag %d", flag)
+			}
+		}},
+		{SectionContext, func(r *reader) {
+			decoded.TraceLength = r.uint32()
+			decoded.TraceWidth = r.uint32()
+		}},
+		{SectionTrace, func(r *reader) { decoded.TraceRoot = r.digest() }},
+		{SectionAuxiliary, func(r *reader) { decoded.AuxiliaryRoots = r.digests() }},
+		{SectionComposition, func(r *reader) { decoded.CompositionRoot = r.digest() }},
+		{SectionOOD, func(r *reader) {
+			decoded.OOD.Current = r.elements()
+			decoded.OOD.Next = r.elements()
+			decoded.OOD.Composition = r.elements()
+		}},
+		{SectionFRI, func(r *reader) {
+			decoded.FRI.Roots = r.digests()
+			decoded.FRI.Remainder = r.elements()
+		}},
+		{SectionPoW, func(r *reader) { decoded.PowNonce = r.uint64() }},
+		{SectionQueries, func(r *reader) {
+			decoded.Queries = make([]Query, r.length(4))
+			for i := range decoded.Queries {
+				query := &decoded.Queries[i]
+				query.Index = r.uint32()
+				query.Trace = r.opening()
+				query.Auxiliary = make([]Opening, r.length(12))
+				for j := range query.Auxiliary {
+					query.Auxiliary[j] = r.opening()
+				}
+				query.Composition = r.opening()
+				query.FRI = make([]Opening, r.length(12))
+				for j := range query.FRI {
+					query.FRI[j] = r.opening()
+				}
+			}
+		}},
+	}
+
+	for _, section := range decoders {
+		if len(data) < 4 {
+			return fmt.Errorf("%w: missing %s section", ErrInvalidProof, section.name)
+		}
+
+		size := uint64(binary.LittleEndian.Uint32(data))
+		if uint64(len(data)-4) < size {
+			return fmt.Errorf("%w: truncated %s section", ErrInvalidProof, section.name)
+		}
+
+		r := &reader{data: data[4 : 4+size]}
+		section.decode(r)
+
+		if r.err == nil && len(r.data) != 0 {
+			r.err = math.ErrTrailingBytes
+		}
+
+		if r.err != nil {
+			return fmt.Errorf("%w: %s section: %w", ErrInvalidProof, section.name, r.err)
+		}
+
+		data = data[4+size:]
+	}
+
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, math.ErrTrailingBytes)
+	}
+
+	if decoded.Parameters.Fingerprint() != fingerprint {
+		return fmt.Errorf("%w: parameter fingerprint mismatch", ErrInvalidProof)
+	}
+
+	*p = decoded
+	return nil
+}
+
+func appendDigests(dst []byte, digests []merkle.Digest) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(digests)))
+	for _, digest := range digests {
+		dst = append(dst, digest[:]...)
+	}
+
+	return dst
+}
+
+func appendOpening(dst []byte, opening Opening) []byte {
+	dst = math.AppendElements(dst, opening.Values)
+	dst = math.AppendElements(dst, opening.Salt)
+	return appendDigests(dst, opening.Path)
+}
+
+// reader decodes a section, remembering the first error
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) take(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if uint64(len(r.data)) < n {
+		r.err = fmt.Errorf("unexpected end of section")
+		return nil
+	}
+
+	out := r.data[:n]
+	r.data = r.data[n:]
+
+	return out
+}
+
+func (r *reader) byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+
+	return 0
+}
+
+// length reads a count of items that take at least minSize bytes each,
+// rejecting counts that cannot fit into the rest of the section
+func (r *reader) length(minSize uint64) int {
+	n := uint64(r.uint32())
+	if r.err == nil && n*minSize > uint64(len(r.data)) {
+		r.err = fmt.Errorf("length %d exceeds section size", n)
+		return 0
+	}
+
+	return int(n)
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+
+	return 0
+}
+
+func (r *reader) digest() merkle.Digest {
+	var digest merkle.Digest
+	copy(digest[:], r.take(merkle.DigestSize))
+
+	return digest
+}
+
+func (r *reader) digests() []merkle.Digest {
+	digests := make([]merkle.Digest, r.length(merkle.DigestSize))
+	for i := range digests {
+		digests[i] = r.digest()
+	}
+
+	return digests
+}
+
+func (r *reader) elements() []*math.PrimeField {
+	if r.err != nil {
+		return nil
+	}
+
+	elements, rest, err := math.ReadElements(r.data)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+
+	r.data = rest
+	return elements
+}
+
+func (r *reader) opening() Opening {
+	return Opening{Values: r.elements(), Salt: r.elements(), Path: r.digests()}
+}
@@ -0,0 +1,192 @@
+package stark
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// friLayer is a committed FRI layer, leaf i holds the pair (f(x_i), f(-x_i)) with -x_i = x_{i+size/2}
+type friLayer struct {
+	values []*math.PrimeField
+	tree   *merkle.Tree
+}
+
+// friFolds returns the number of folds needed to reduce the degree bound to at most maxRemainderDegree + 1
+func friFolds(degreeBound int, maxRemainderDegree uint32) int {
+	folds := 0
+	for degreeBound > int(maxRemainderDegree)+1 {
+		degreeBound /= 2
+		folds++
+	}
+
+	return folds
+}
+
+// friLayerDomain returns the offset and the root of unity of the domain of layer l
+func friLayerDomain(d *domain, l int) (offset, root *math.PrimeField) {
+	exponent := new(big.Int).Lsh(big.NewInt(1), uint(l))
+
+	return new(math.PrimeField).Exp(d.offset, exponent), new(math.PrimeField).Exp(d.root, exponent)
+}
+
+// friFold folds the pair (f(x), f(-x)) into f'(x²) = (f(x) + f(-x)) / 2 + β·(f(x) - f(-x)) / (2x)
+func friFold(positive, negative, x, beta *math.PrimeField) *math.PrimeField {
+	twoInv := new(math.PrimeField).Inv(math.NewPrimeField(2))
+
+	even := new(math.PrimeField).Mul(new(math.PrimeField).Add(positive, negative), twoInv)
+	odd := new(math.PrimeField).Div(new(math.PrimeField).Sub(positive, negative), new(math.PrimeField).Add(x, x))
+
+	return new(math.PrimeField).Add(even, new(math.PrimeField).Mul(beta, odd))
+}
+
+// friCommit commits to the FRI layers of values, which must be evaluations of a polynomial
+// of degree below degreeBound on the evaluation domain. It stops with ctx.Err() between layers once ctx is done.
+func friCommit(ctx context.Context, t *transcript, hasher merkle.Hasher, d *domain, values []*math.PrimeField, degreeBound int, params Parameters) ([]*friLayer, FRIProof, error) {
+	folds := friFolds(degreeBound, params.MaxRemainderDegree)
+
+	layers := make([]*friLayer, 0, folds)
+	proof := FRIProof{Roots: make([]merkle.Digest, 0, folds)}
+
+	for l := range folds {
+		if err := ctx.Err(); err != nil {
+			return nil, FRIProof{}, err
+		}
+
+		half := len(values) / 2
+
+		leaves := make([]merkle.Digest, half)
+		for i := range leaves {
+			leaves[i] = hasher.HashElements([]*math.PrimeField{values[i], values[i+half]})
+		}
+
+		tree, err := merkle.NewTree(hasher, leaves)
+		if err != nil {
+			return nil, FRIProof{}, err
+		}
+
+		layers = append(layers, &friLayer{values: values, tree: tree})
+		proof.Roots = append(proof.Roots, tree.Root())
+
+		t.absorbDigest(tree.Root())
+		beta := t.drawElement()
+
+		offset, root := friLayerDomain(d, l)
+		x := offset.Copy()
+
+		folded := make([]*math.PrimeField, half)
+		for i := range folded {
+			folded[i] = friFold(values[i], values[i+half], x, beta)
+			x = x.Mul(x, root)
+		}
+
+		values = folded
+		degreeBound /= 2
+	}
+
+	offset, root := friLayerDomain(d, folds)
+	remainder := new(math.PrimeField).CosetINTT(root, offset, math.NewPolynom(values))
+
+	for i := degreeBound; i < remainder.Len(); i++ {
+		if !remainder.At(i).IsZero() {
+			return nil, FRIProof{}, fmt.Errorf("fri remainder has degree %d, expected below %d", remainder.Degree(), degreeBound)
+		}
+	}
+
+	proof.Remainder = remainder.Coefficients[:degreeBound]
+	t.absorbElements(proof.Remainder)
+
+	return layers, proof, nil
+}
+
+// friOpen returns the openings of every layer along the path of the query index
+func friOpen(layers []*friLayer, index int) []Opening {
+	openings := make([]Opening, len(layers))
+
+	for l, layer := range layers {
+		half := len(layer.values) / 2
+		index %= half
+
+		openings[l] = Opening{
+			Values: []*math.PrimeField{layer.values[index], layer.values[index+half]},
+			Path:   layer.tree.Open(index),
+		}
+	}
+
+	return openings
+}
+
+// friVerifier holds the FRI challenges replayed from the transcript
+type friVerifier struct {
+	betas     []*math.PrimeField
+	remainder *math.Polynom
+}
+
+// newFriVerifier replays the FRI commitments, the degree bound must be reduced to the remainder size
+func newFriVerifier(t *transcript, proof *FRIProof, degreeBound int, params Parameters) (*friVerifier, error) {
+	folds := friFolds(degreeBound, params.MaxRemainderDegree)
+
+	if len(proof.Roots) != folds {
+		return nil, fmt.Errorf("expected %d fri layers, got %d", folds, len(proof.Roots))
+	}
+
+	if len(proof.Remainder) != degreeBound>>folds {
+		return nil, fmt.Errorf("expected %d remainder coefficients, got %d", degreeBound>>folds, len(proof.Remainder))
+	}
+
+	betas := make([]*math.PrimeField, folds)
+	for l, root := range proof.Roots {
+		t.absorbDigest(root)
+		betas[l] = t.drawElement()
+	}
+
+	t.absorbElements(proof.Remainder)
+
+	return &friVerifier{betas: betas, remainder: math.NewPolynom(proof.Remainder)}, nil
+}
+
+// verifyQuery checks that value, the evaluation of the first layer at index, is consistent
+// with the opened layers and the remainder
+func (f *friVerifier) verifyQuery(hasher merkle.Hasher, d *domain, proof *FRIProof, index int, value *math.PrimeField, openings []Opening) error {
+	if len(openings) != len(f.betas) {
+		return fmt.Errorf("expected %d fri openings, got %d", len(f.betas), len(openings))
+	}
+
+	size := d.size
+	for l, opening := range openings {
+		half := size / 2
+		pair := index % half
+
+		if len(opening.Values) != 2 {
+			return fmt.Errorf("fri layer %d opening must have 2 values, got %d", l, len(opening.Values))
+		}
+
+		if !merkle.Verify(hasher, proof.Roots[l], bits.Len(uint(half))-1, pair, hasher.HashElements(opening.Values), opening.Path) {
+			return fmt.Errorf("fri layer %d: invalid Merkle path", l)
+		}
+
+		if !opening.Values[index/half].Equals(value) {
+			return fmt.Errorf("fri layer %d: value does not match the previous layer", l)
+		}
+
+		offset, root := friLayerDomain(d, l)
+		x := new(math.PrimeField).Mul(offset, new(math.PrimeField).Exp(root, big.NewInt(int64(pair))))
+
+		value = friFold(opening.Values[0], opening.Values[1], x, f.betas[l])
+		index = pair
+		size = half
+	}
+
+	offset, root := friLayerDomain(d, len(openings))
+	x := new(math.PrimeField).Mul(offset, new(math.PrimeField).Exp(root, big.NewInt(int64(index))))
+
+	if !f.remainder.EvalAt(x).Equals(value) {
+		return fmt.Errorf("fri remainder does not match the last layer")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package stark
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+	"runtime"
+	"sync"
+
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// grindingCheckInterval is the number of nonces a worker tries between cancellation checks
+const grindingCheckInterval = 1 << 10
+
+// powDigest returns the digest the proof-of-work is checked against
+func (t *transcript) powDigest(nonce uint64) merkle.Digest {
+	return t.hasher.Merge(t.state, t.hasher.HashElements(nonceElements(nonce)))
+}
+
+// nonceElements splits the nonce into two 32-bit field elements
+func nonceElements(nonce uint64) []*math.PrimeField {
+	return []*math.PrimeField{
+		math.NewPrimeFieldUint64(nonce & 0xffffffff),
+		math.NewPrimeFieldUint64(nonce >> 32),
+	}
+}
+
+// leadingZeros returns the number of leading zero bits of the digest read as a big-endian integer
+func leadingZeros(digest merkle.Digest) int {
+	return bits.LeadingZeros64(binary.BigEndian.Uint64(digest[:8]))
+}
+
+// checkProofOfWork checks in constant time that the nonce gives enough leading zero bits
+func (t *transcript) checkProofOfWork(nonce uint64, grindingBits uint32) bool {
+	return leadingZeros(t.powDigest(nonce)) >= int(grindingBits)
+}
+
+// absorbNonce mixes the proof-of-work nonce into the transcript
+func (t *transcript) absorbNonce(nonce uint64) {
+	t.absorbElements(nonceElements(nonce))
+}
+
+// grind searches for a nonce giving at least grindingBits leading zero bits.
+// Nonces are split between one worker per CPU, the search stops when one is found or ctx is done.
+func (t *transcript) grind(ctx context.Context, grindingBits uint32) (uint64, error) {
+	if grindingBits == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := runtime.NumCPU()
+	found := make(chan uint64, workers)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+
+		go func(start uint64) {
+			defer wg.Done()
+
+			for nonce := start; ; nonce += uint64(workers) {
+				if (nonce/uint64(workers))%grindingCheckInterval == 0 && ctx.Err() != nil {
+					return
+				}
+
+				if t.checkProofOfWork(nonce, grindingBits) {
+					found <- nonce
+					cancel()
+					return
+				}
+			}
+		}(uint64(w))
+	}
+
+	wg.Wait()
+
+	select {
+	case nonce := <-found:
+		return nonce, nil
+	default:
+		return 0, ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package stark
+
+import (
+	"fmt"
+
+	"github.com/KyrylR/simple-air/air"
+)
+
+// saltElements is the number of random elements appended to every committed row in zero-knowledge mode
+const saltElements = 4
+
+// zkMaskDegree returns the number of random coefficients masking every trace column in zero-knowledge mode.
+// Every column is revealed at z, z·ω, at every query position x and, through the composition opened at x, at x·ω:
+// a random mask of that degree hides the trace values. The composition parts are revealed at z and at every
+// query position, which the same mask degree covers.
+func zkMaskDegree(numQueries int) int {
+	return 2*numQueries + 2
+}
+
+// traceBound returns the degree bound of the trace polynomials, the trace length rounded up
+// to a power of two that fits the masks in zero-knowledge mode
+func traceBound(traceLength, numQueries int, zeroKnowledge bool) int {
+	bound := traceLength
+	if zeroKnowledge {
+		for bound < traceLength+zkMaskDegree(numQueries) {
+			bound *= 2
+		}
+	}
+
+	return bound
+}
+
+// layout describes the degrees and widths of the committed polynomials
+type layout struct {
+	traceLength int
+	traceWidth  int
+
+	// auxWidths are the numbers of columns of the auxiliary segments
+	auxWidths []int
+
+	// challenges are the numbers of challenges drawn before each auxiliary segment is built
+	challenges []int
+
+	// traceBound is the degree bound of the trace polynomials, which is larger than
+	// the trace length in zero-knowledge mode because of the masking polynomials
+	traceBound int
+
+	// maskDegree is the degree bound of the random polynomials masking the trace and the composition parts,
+	// 0 without zero-knowledge
+	maskDegree int
+
+	// compositionColumns is the number of columns of degree below the trace length
+	// the composition polynomial is split into
+	compositionColumns int
+
+	// compositionWidth is the number of committed composition columns,
+	// including the random column in zero-knowledge mode
+	compositionWidth int
+
+	zeroKnowledge bool
+}
+
+// newLayout checks that the AIR can be proven with the parameters and returns the layout of the proof
+func newLayout(a air.AIR, params Parameters) (*layout, error) {
+	n := a.TraceLength()
+	if n < 2 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("trace length must be a power of two not less than 2, got %d", n)
+	}
+
+	if a.TraceWidth() <= 0 {
+		return nil, fmt.Errorf("trace width must be positive, got %d", a.TraceWidth())
+	}
+
+	for _, assertion := range a.Assertions() {
+		if assertion.Step < 0 || assertion.Step >= n || assertion.Column < 0 || assertion.Column >= a.TraceWidth() {
+			return nil, fmt.Errorf("assertion at step %d column %d is outside of the trace", assertion.Step, assertion.Column)
+		}
+	}
+
+	if err := air.CheckPeriodic(air.Periodic(a), n); err != nil {
+		return nil, err
+	}
+
+	l := &layout{
+		traceLength:   n,
+		traceWidth:    a.TraceWidth(),
+		traceBound:    traceBound(n, int(params.NumQueries), params.ZeroKnowledge),
+		zeroKnowledge: params.ZeroKnowledge,
+	}
+
+	for k, aux := range auxiliary(a) {
+		if aux.Width() <= 0 {
+			return nil, fmt.Errorf("auxiliary segment %d width must be positive, got %d", k+1, aux.Width())
+		}
+
+		l.auxWidths = append(l.auxWidths, aux.Width())
+		l.challenges = append(l.challenges, aux.NumChallenges())
+	}
+
+	if params.ZeroKnowledge {
+		l.maskDegree = zkMaskDegree(int(params.NumQueries))
+	}
+
+	l.compositionColumns = compositionColumns(n, l.traceBound, a.TransitionDegree())
+
+	l.compositionWidth = l.compositionColumns
+	if params.ZeroKnowledge {
+		l.compositionWidth++
+	}
+
+	size := n * int(params.BlowupFactor)
+	if size < l.compositionColumns*n || size < 2*l.traceBound {
+		return nil, fmt.Errorf("blowup factor %d is too small for transition degree %d and trace degree bound %d",
+			params.BlowupFactor, a.TransitionDegree(), l.traceBound)
+	}
+
+	return l, nil
+}
+
+// compositionColumns returns the number of columns of degree below the trace length n
+// the composition polynomial of a trace with the degree bound and transition degree d is split into.
+//
+// C(x) = Σ α_i·t_i(x) / Z_T(x) + Σ β_k·(T_{c_k}(x) - v_k) / (x - ω^{s_k})
+// has degree at most d·(traceBound - 1) - (n - 1) from transitions and traceBound - 2 from assertions.
+func compositionColumns(n, traceBound, d int) int {
+	degree := max(d*(traceBound-1)-(n-1), traceBound-2)
+	return max((degree+n)/n, 1)
+}
+
+// width returns the number of columns of the main and auxiliary segments
+func (l *layout) width() int {
+	width := l.traceWidth
+	for _, w := range l.auxWidths {
+		width += w
+	}
+
+	return width
+}
@@ -0,0 +1,135 @@
+package stark
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/KyrylR/simple-air/hash/keccak"
+	"github.com/KyrylR/simple-air/hash/poseidon2"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// HashFunction identifies the hash function used for commitments and the transcript
+type HashFunction uint8
+
+const (
+	// HashSHA256 is SHA-256 over the canonical encoding of field elements
+	HashSHA256 HashFunction = iota + 1
+
+	// HashPoseidon2 is the Poseidon2 sponge of width 12 over the prime field
+	HashPoseidon2
+
+	// HashKeccak256 is Keccak-256 over field elements encoded as EVM words, which contracts verify cheaply
+	HashKeccak256
+)
+
+// Hasher returns the hasher for the hash function
+func (h HashFunction) Hasher() (merkle.Hasher, error) {
+	switch h {
+	case HashSHA256:
+		return merkle.SHA256{}, nil
+	case HashPoseidon2:
+		return poseidon2.NewHasher(12)
+	case HashKeccak256:
+		return keccak.Hasher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown hash function %d", h)
+	}
+}
+
+// String returns the name of the hash function
+func (h HashFunction) String() string {
+	switch h {
+	case HashSHA256:
+		return "sha256"
+	case HashPoseidon2:
+		return "poseidon2"
+	case HashKeccak256:
+		return "keccak256"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(h))
+	}
+}
+
+// Parameters are the proof options shared by the prover and the verifier
+type Parameters struct {
+	// BlowupFactor is the ratio between the evaluation domain and the trace length
+	BlowupFactor uint32
+
+	// NumQueries is the number of FRI queries
+	NumQueries uint32
+
+	// GrindingBits is the number of leading zero bits required from the proof-of-work
+	GrindingBits uint32
+
+	// MaxRemainderDegree is the maximal degree of the last FRI layer sent in the clear
+	MaxRemainderDegree uint32
+
+	// Hash is the hash function used for commitments and the transcript
+	Hash HashFunction
+
+	// ZeroKnowledge masks the trace and the composition polynomial and salts committed rows,
+	// so that the proof reveals nothing about the trace beyond the public statement
+	ZeroKnowledge bool
+}
+
+// DefaultParameters returns parameters suitable for tests and small traces
+func DefaultParameters() Parameters {
+	return Parameters{
+		BlowupFactor:       8,
+		NumQueries:         32,
+		GrindingBits:       0,
+		MaxRemainderDegree: 7,
+		Hash:               HashSHA256,
+	}
+}
+
+// Validate checks that the parameters are usable
+func (p Parameters) Validate() error {
+	if p.BlowupFactor < 2 || p.BlowupFactor&(p.BlowupFactor-1) != 0 {
+		return fmt.Errorf("blowup factor must be a power of two not less than 2, got %d", p.BlowupFactor)
+	}
+
+	if p.NumQueries == 0 {
+		return fmt.Errorf("number of queries must be positive")
+	}
+
+	if p.GrindingBits > 32 {
+		return fmt.Errorf("grinding bits must not exceed 32, got %d", p.GrindingBits)
+	}
+
+	if (p.MaxRemainderDegree+1)&p.MaxRemainderDegree != 0 {
+		return fmt.Errorf("max remainder degree must be one less than a power of two, got %d", p.MaxRemainderDegree)
+	}
+
+	if _, err := p.Hash.Hasher(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters as little-endian integers in field order
+func (p Parameters) MarshalBinary() ([]byte, error) {
+	out := binary.LittleEndian.AppendUint32(nil, p.BlowupFactor)
+	out = binary.LittleEndian.AppendUint32(out, p.NumQueries)
+	out = binary.LittleEndian.AppendUint32(out, p.GrindingBits)
+	out = binary.LittleEndian.AppendUint32(out, p.MaxRemainderDegree)
+
+	out = append(out, byte(p.Hash))
+
+	if p.ZeroKnowledge {
+		return append(out, 1), nil
+	}
+
+	return append(out, 0), nil
+}
+
+// Fingerprint returns the first 8 bytes of the SHA-256 hash of the encoded parameters
+func (p Parameters) Fingerprint() [8]byte {
+	data, _ := p.MarshalBinary()
+	sum := sha256.Sum256(data)
+
+	return [8]byte(sum[:8])
+}
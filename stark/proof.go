@@ -0,0 +1,80 @@
+package stark
+
+import (
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// Opening is a committed row together with its Merkle authentication path
+type Opening struct {
+	Values []*math.PrimeField
+
+	// Salt are the random elements hashed together with the row in zero-knowledge mode
+	Salt []*math.PrimeField
+
+	Path merkle.Path
+}
+
+// Query holds everything the verifier needs to check one position of the evaluation domain
+type Query struct {
+	// Index is the position in the evaluation domain
+	Index uint32
+
+	// Trace is the trace row at Index
+	Trace Opening
+
+	// Auxiliary is the row of every auxiliary trace segment at Index
+	Auxiliary []Opening
+
+	// Composition is the composition polynomial evaluations at Index
+	Composition Opening
+
+	// FRI holds the folded pair of every FRI layer that the query passes through
+	FRI []Opening
+}
+
+// OODFrame holds the out-of-domain evaluations sent by the prover
+type OODFrame struct {
+	// Current is the evaluation of every main and auxiliary trace column at z
+	Current []*math.PrimeField
+
+	// Next is the evaluation of every main and auxiliary trace column at z·ω
+	Next []*math.PrimeField
+
+	// Composition is the evaluation of the composition polynomial at z
+	Composition []*math.PrimeField
+}
+
+// FRIProof holds the FRI layer commitments
+type FRIProof struct {
+	// Roots are the Merkle roots of the committed FRI layers
+	Roots []merkle.Digest
+
+	// Remainder are the coefficients of the last FRI layer
+	Remainder []*math.PrimeField
+}
+
+// Proof is a STARK proof that an execution trace satisfies an AIR
+type Proof struct {
+	Parameters Parameters
+
+	// TraceLength is the number of rows of the execution trace
+	TraceLength uint32
+
+	// TraceWidth is the number of columns of the execution trace
+	TraceWidth uint32
+
+	TraceRoot merkle.Digest
+
+	// AuxiliaryRoots are the commitments to the auxiliary trace segments
+	AuxiliaryRoots []merkle.Digest
+
+	CompositionRoot merkle.Digest
+
+	OOD     OODFrame
+	FRI     FRIProof
+	Queries []Query
+
+	// PowNonce is the proof-of-work nonce found by grinding
+	PowNonce uint64
+}
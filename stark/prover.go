@@ -0,0 +1,305 @@
+package stark
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// Prove generates a proof that the trace satisfies the AIR, see ProveContext
+func Prove(a air.AIR, trace air.ExecutionTrace, params Parameters) (*Proof, error) {
+	return ProveContext(context.Background(), a, trace, params)
+}
+
+// ProveContext generates a proof that the trace satisfies the AIR.
+//
+// The prover commits to the low-degree extension of the trace, of every auxiliary segment of the AIR built with challenges
+// drawn after the previous commitment, and of the composition polynomial,
+// samples an out-of-domain point z and sends the trace at z and z·ω and the composition columns at z,
+// then proves with FRI that the DEEP composition polynomial built from these values has low degree.
+// Before the query positions are drawn, the prover grinds a proof-of-work nonce.
+// The prover checks ctx between its phases, after every commitment and FRI layer and during grinding,
+// and returns ctx.Err() once ctx is done.
+func ProveContext(ctx context.Context, a air.AIR, trace air.ExecutionTrace, params Parameters) (*Proof, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	l, err := newLayout(a, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = air.Check(a, trace); err != nil {
+		return nil, fmt.Errorf("trace does not satisfy the air: %w", err)
+	}
+
+	hasher, _ := params.Hash.Hasher()
+	t := newTranscript(hasher, params, a)
+	d := newDomain(a.TraceLength(), int(params.BlowupFactor))
+
+	// Trace commitment
+	main, err := commitSegment(hasher, d, l, trace)
+	if err != nil {
+		return nil, err
+	}
+	t.absorbDigest(main.tree.Root())
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	segments := []*segment{main}
+
+	// Auxiliary segment commitments, the challenges of every segment are drawn after the previous commitment
+	built := []air.ExecutionTrace{trace}
+	challenges := make([][]*math.PrimeField, 0, len(l.auxWidths))
+
+	for k, aux := range auxiliary(a) {
+		challenges = append(challenges, t.drawElements(l.challenges[k]))
+
+		built = append(built, aux.Build(built, challenges[k]))
+		if err = air.CheckSegment(aux, built, challenges[k]); err != nil {
+			return nil, fmt.Errorf("auxiliary segment %d does not satisfy the air: %w", k+1, err)
+		}
+
+		auxSegment, err := commitSegment(hasher, d, l, built[k+1])
+		if err != nil {
+			return nil, err
+		}
+		t.absorbDigest(auxSegment.tree.Root())
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, auxSegment)
+	}
+
+	c, err := newConstraints(a, challenges)
+	if err != nil {
+		return nil, err
+	}
+
+	// Composition commitment
+	coefficients := drawCompositionCoefficients(t, c)
+
+	compositionValues := make([]*math.PrimeField, d.size)
+	for i, x := range d.points {
+		compositionValues[i] = evaluateComposition(c, d, coefficients, x, segmentRow(segments, i), segmentRow(segments, (i+d.blowup)%d.size))
+	}
+
+	compositionPoly := d.interpolateExtended(compositionValues)
+
+	if compositionPoly.Degree() >= l.compositionColumns*d.traceLength {
+		return nil, fmt.Errorf("composition polynomial has degree %d, expected below %d", compositionPoly.Degree(), l.compositionColumns*d.traceLength)
+	}
+
+	compositionParts := make([]*math.Polynom, l.compositionWidth)
+	for k := range l.compositionColumns {
+		compositionParts[k] = math.NewPolynom(compositionPoly.Coefficients[k*d.traceLength : (k+1)*d.traceLength])
+	}
+
+	if l.zeroKnowledge {
+		maskComposition(compositionParts, l)
+
+		// The random column masks the DEEP composition polynomial and thus every FRI layer
+		compositionParts[l.compositionColumns] = randomPolynom(l.traceBound)
+	}
+
+	compositionLDE := make([][]*math.PrimeField, l.compositionWidth)
+	for k, part := range compositionParts {
+		compositionLDE[k] = d.extend(part)
+	}
+
+	compositionSalts := drawSalts(l, d.size)
+	compositionTree, err := commitRows(hasher, compositionLDE, compositionSalts, d.size)
+	if err != nil {
+		return nil, err
+	}
+	t.absorbDigest(compositionTree.Root())
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Out-of-domain evaluations
+	z := drawOODPoint(t, d)
+	zNext := new(math.PrimeField).Mul(z, d.traceRoot)
+
+	ood := OODFrame{
+		Current:     make([]*math.PrimeField, 0, l.width()),
+		Next:        make([]*math.PrimeField, 0, l.width()),
+		Composition: make([]*math.PrimeField, l.compositionWidth),
+	}
+
+	for _, s := range segments {
+		for _, column := range s.columns {
+			ood.Current = append(ood.Current, column.EvalAt(z))
+			ood.Next = append(ood.Next, column.EvalAt(zNext))
+		}
+	}
+
+	for k, part := range compositionParts {
+		ood.Composition[k] = part.EvalAt(z)
+	}
+
+	t.absorbElements(ood.elements())
+
+	// DEEP composition and FRI
+	deep := drawDeepCoefficients(t, l.width(), l.compositionWidth)
+
+	deepValues := make([]*math.PrimeField, d.size)
+	for i, x := range d.points {
+		deepValues[i] = evaluateDeep(deep, &ood, z, zNext, x, segmentRow(segments, i), row(compositionLDE, i))
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	layers, friProof, err := friCommit(ctx, t, hasher, d, deepValues, l.traceBound, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Proof-of-work
+	nonce, err := t.grind(ctx, params.GrindingBits)
+	if err != nil {
+		return nil, err
+	}
+	t.absorbNonce(nonce)
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Queries
+	indices := t.drawIndices(int(params.NumQueries), d.size)
+
+	queries := make([]Query, len(indices))
+	for q, index := range indices {
+		queries[q] = Query{
+			Index:       uint32(index),
+			Trace:       main.open(index),
+			Composition: Opening{Values: row(compositionLDE, index), Salt: salt(compositionSalts, index), Path: compositionTree.Open(index)},
+			FRI:         friOpen(layers, index),
+		}
+
+		for _, s := range segments[1:] {
+			queries[q].Auxiliary = append(queries[q].Auxiliary, s.open(index))
+		}
+	}
+
+	auxRoots := make([]merkle.Digest, 0, len(segments)-1)
+	for _, s := range segments[1:] {
+		auxRoots = append(auxRoots, s.tree.Root())
+	}
+
+	return &Proof{
+		Parameters:      params,
+		TraceLength:     uint32(a.TraceLength()),
+		TraceWidth:      uint32(a.TraceWidth()),
+		TraceRoot:       main.tree.Root(),
+		AuxiliaryRoots:  auxRoots,
+		CompositionRoot: compositionTree.Root(),
+		OOD:             ood,
+		FRI:             friProof,
+		Queries:         queries,
+		PowNonce:        nonce,
+	}, nil
+}
+
+// drawOODPoint draws z outside of the trace and evaluation domains
+func drawOODPoint(t *transcript, d *domain) *math.PrimeField {
+	for {
+		if z := t.drawElement(); !d.inDomains(z) {
+			return z
+		}
+	}
+}
+
+// elements returns all out-of-domain values in the order they are absorbed into the transcript
+func (o *OODFrame) elements() []*math.PrimeField {
+	output := append([]*math.PrimeField{}, o.Current...)
+	output = append(output, o.Next...)
+
+	return append(output, o.Composition...)
+}
+
+// row returns the values of all columns at index
+func row(columns [][]*math.PrimeField, index int) []*math.PrimeField {
+	output := make([]*math.PrimeField, len(columns))
+	for j, column := range columns {
+		output[j] = column[index]
+	}
+
+	return output
+}
+
+// segment is a committed trace segment: its column polynomials, their low-degree extension and its Merkle tree
+type segment struct {
+	columns []*math.Polynom
+	lde     [][]*math.PrimeField
+	salts   [][]*math.PrimeField
+	tree    *merkle.Tree
+}
+
+// commitSegment interpolates the columns of the trace, masks them in zero-knowledge mode
+// and commits to the rows of their low-degree extension
+func commitSegment(hasher merkle.Hasher, d *domain, l *layout, trace air.ExecutionTrace) (*segment, error) {
+	s := &segment{
+		columns: make([]*math.Polynom, trace.Width()),
+		lde:     make([][]*math.PrimeField, trace.Width()),
+		salts:   drawSalts(l, d.size),
+	}
+
+	for j := range s.columns {
+		column := make([]*math.PrimeField, len(trace))
+		for i, row := range trace {
+			column[i] = row.At(j)
+		}
+
+		s.columns[j] = d.interpolate(column)
+		if l.zeroKnowledge {
+			s.columns[j] = maskTrace(s.columns[j], l)
+		}
+		s.lde[j] = d.extend(s.columns[j])
+	}
+
+	tree, err := commitRows(hasher, s.lde, s.salts, d.size)
+	if err != nil {
+		return nil, err
+	}
+	s.tree = tree
+
+	return s, nil
+}
+
+// open returns the committed row at index
+func (s *segment) open(index int) Opening {
+	return Opening{Values: row(s.lde, index), Salt: salt(s.salts, index), Path: s.tree.Open(index)}
+}
+
+// segmentRow returns the values of all columns of all segments at index
+func segmentRow(segments []*segment, index int) []*math.PrimeField {
+	var output []*math.PrimeField
+	for _, s := range segments {
+		output = append(output, row(s.lde, index)...)
+	}
+
+	return output
+}
+
+// commitRows builds a Merkle tree whose leaves are the hashes of the rows of columns followed by their salt
+func commitRows(hasher merkle.Hasher, columns [][]*math.PrimeField, salts [][]*math.PrimeField, size int) (*merkle.Tree, error) {
+	leaves := make([]merkle.Digest, size)
+	for i := range leaves {
+		leaves[i] = hashRow(hasher, row(columns, i), salt(salts, i))
+	}
+
+	return merkle.NewTree(hasher, leaves)
+}
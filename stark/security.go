@@ -0,0 +1,322 @@
+package stark
+
+import (
+	"fmt"
+	gomath "math"
+	"math/bits"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+const (
+	// GoldilocksBits is the size of the base field in bits
+	GoldilocksBits = 64
+
+	// collisionResistance is the collision resistance of 256-bit digests in bits
+	collisionResistance = merkle.DigestSize * 8 / 2
+)
+
+// SecurityInput describes a proof configuration for security estimation
+type SecurityInput struct {
+	// FieldBits is the size of the field challenges are drawn from: 64 for Goldilocks,
+	// or 128 to estimate a quadratic extension, which the prover does not implement
+	FieldBits int
+
+	TraceLength         int
+	BlowupFactor        int
+	NumQueries          int
+	GrindingBits        int
+	MaxConstraintDegree int
+
+	// MaxRemainderDegree is the largest degree of the FRI remainder, which sets the number of folding layers
+	MaxRemainderDegree int
+
+	// ZeroKnowledge raises the degree bound of the trace above the trace length to fit the masks,
+	// which lowers the rate of the code checked by FRI
+	ZeroKnowledge bool
+}
+
+// SecurityLevel is the estimated security of a configuration in bits
+type SecurityLevel struct {
+	// Conjectured assumes the commonly used conjecture that every query adds log2(blowup) bits
+	Conjectured int
+
+	// Provable uses the unique decoding regime, where every query adds -log2((1 + 1/blowup) / 2) bits
+	Provable int
+}
+
+// EstimateSecurity returns the conjectured and provable security of the configuration.
+//
+// Both estimates are bounded by the soundness of drawing challenges from the field and by
+// the collision resistance of the hash function. One bit is subtracted for the union of both error terms.
+// Queries are counted against the rate of the code, the degree bound of the trace over the evaluation domain size.
+// The trace length and the blowup factor must be positive.
+func EstimateSecurity(in SecurityInput) (SecurityLevel, error) {
+	if in.TraceLength <= 0 {
+		return SecurityLevel{}, fmt.Errorf("trace length must be positive, got %d", in.TraceLength)
+	}
+
+	if in.BlowupFactor <= 0 {
+		return SecurityLevel{}, fmt.Errorf("blowup factor must be positive, got %d", in.BlowupFactor)
+	}
+
+	bound := traceBound(in.TraceLength, in.NumQueries, in.ZeroKnowledge)
+
+	ldeBits := gomath.Log2(float64(in.TraceLength * in.BlowupFactor))
+	rate := float64(bound) / float64(in.TraceLength*in.BlowupFactor)
+
+	// Challenges drawn from the field may hit the evaluation domain
+	fieldSecurity := float64(in.FieldBits) - ldeBits
+	// Out-of-domain sampling fails if z is a root of the difference of two distinct
+	// composition polynomials of degree below max(d, 1)·lde
+	deepSecurity := float64(in.FieldBits) - ldeBits - gomath.Log2(float64(max(in.MaxConstraintDegree, 1)))
+
+	// Every FRI folding challenge may be unlucky on each element of the domain
+	layers := friFolds(bound, uint32(in.MaxRemainderDegree))
+	friSecurity := float64(in.FieldBits) - ldeBits - gomath.Log2(float64(layers+1))
+
+	conjecturedQueries := -float64(in.NumQueries)*gomath.Log2(rate) + float64(in.GrindingBits)
+
+	provableQueries := -float64(in.NumQueries)*gomath.Log2((1+rate)/2) + float64(in.GrindingBits)
+
+	conjectured := min(fieldSecurity, conjecturedQueries) - 1
+	provable := min(deepSecurity, friSecurity, provableQueries) - 1
+
+	return SecurityLevel{
+		Conjectured: max(0, min(int(gomath.Floor(conjectured)), collisionResistance)),
+		Provable:    max(0, min(int(gomath.Floor(provable)), collisionResistance)),
+	}, nil
+}
+
+// Security returns the estimated security of the parameters for proofs over the Goldilocks field
+func (p Parameters) Security(traceLength, maxConstraintDegree int) (SecurityLevel, error) {
+	return EstimateSecurity(SecurityInput{
+		FieldBits:           GoldilocksBits,
+		TraceLength:         traceLength,
+		BlowupFactor:        int(p.BlowupFactor),
+		NumQueries:          int(p.NumQueries),
+		GrindingBits:        int(p.GrindingBits),
+		MaxConstraintDegree: maxConstraintDegree,
+		MaxRemainderDegree:  int(p.MaxRemainderDegree),
+		ZeroKnowledge:       p.ZeroKnowledge,
+	})
+}
+
+// SecurityTarget describes the computation and the security to search parameters for
+type SecurityTarget struct {
+	// Bits is the required security level
+	Bits int
+
+	// Provable selects the provable instead of the conjectured estimate
+	Provable bool
+
+	// ZeroKnowledge selects parameters for zero-knowledge proofs
+	ZeroKnowledge bool
+
+	// FieldBits is the size of the field challenges are drawn from, only GoldilocksBits can be proven
+	FieldBits           int
+	TraceLength         int
+	TraceWidth          int
+	MaxConstraintDegree int
+
+	// AuxiliaryWidths are the numbers of columns of the auxiliary segments, if any
+	AuxiliaryWidths []int
+
+	// MaxBlowup and MaxGrinding bound the search, they trade prover time for proof size
+	MaxBlowup   int
+	MaxGrinding int
+}
+
+// SuggestParameters returns the parameters with the smallest estimated proof size reaching the target.
+// Ties are broken by the smaller blowup factor and then by less grinding, which are cheaper for the prover.
+// The prover draws challenges from the base field, so the target must be over the Goldilocks field.
+func SuggestParameters(target SecurityTarget) (Parameters, SecurityLevel, error) {
+	if target.FieldBits != GoldilocksBits {
+		return Parameters{}, SecurityLevel{}, fmt.Errorf("challenges are drawn from the %d-bit Goldilocks field, got a %d-bit field",
+			GoldilocksBits, target.FieldBits)
+	}
+
+	if target.TraceLength <= 0 {
+		return Parameters{}, SecurityLevel{}, fmt.Errorf("trace length must be positive, got %d", target.TraceLength)
+	}
+
+	var (
+		best      Parameters
+		bestLevel SecurityLevel
+		bestSize  = -1
+	)
+
+	minBlowup := 2
+	for minBlowup < max(target.MaxConstraintDegree-1, 1) {
+		minBlowup *= 2
+	}
+
+	for blowup := minBlowup; blowup <= target.MaxBlowup; blowup *= 2 {
+		for grinding := 0; grinding <= min(target.MaxGrinding, 32); grinding++ {
+			params := DefaultParameters()
+			params.BlowupFactor = uint32(blowup)
+			params.GrindingBits = uint32(grinding)
+			params.ZeroKnowledge = target.ZeroKnowledge
+
+			in := SecurityInput{
+				FieldBits:           target.FieldBits,
+				TraceLength:         target.TraceLength,
+				BlowupFactor:        blowup,
+				GrindingBits:        grinding,
+				MaxConstraintDegree: target.MaxConstraintDegree,
+				MaxRemainderDegree:  int(params.MaxRemainderDegree),
+				ZeroKnowledge:       target.ZeroKnowledge,
+			}
+
+			queries, level, ok := minQueries(in, target)
+			if !ok {
+				continue
+			}
+			params.NumQueries = uint32(queries)
+
+			// The masks of zero-knowledge proofs need a larger domain
+			l := targetLayout(target, params)
+			size := target.TraceLength * blowup
+			if size < 2*l.traceBound || size < l.compositionColumns*target.TraceLength {
+				continue
+			}
+
+			proofSize := l.proofSize(params)
+			if bestSize < 0 || proofSize < bestSize {
+				best, bestLevel, bestSize = params, level, proofSize
+			}
+		}
+	}
+
+	if bestSize < 0 {
+		return Parameters{}, SecurityLevel{}, fmt.Errorf("no parameters reach %d bits over a %d-bit field with blowup up to %d",
+			target.Bits, target.FieldBits, target.MaxBlowup)
+	}
+
+	return best, bestLevel, nil
+}
+
+// minQueries returns the smallest number of queries reaching the target, if any
+func minQueries(in SecurityInput, target SecurityTarget) (int, SecurityLevel, bool) {
+	const maxQueries = 512
+
+	for queries := 1; queries <= maxQueries; queries++ {
+		in.NumQueries = queries
+		level, err := EstimateSecurity(in)
+		if err != nil {
+			return 0, SecurityLevel{}, false
+		}
+
+		bits := level.Conjectured
+		if target.Provable {
+			bits = level.Provable
+		}
+
+		if bits >= target.Bits {
+			return queries, level, true
+		}
+	}
+
+	return 0, SecurityLevel{}, false
+}
+
+// EstimateProofSize returns the size in bytes of an encoded proof of the AIR with the parameters.
+// The size is computed by encoding a proof of the same shape, so it includes every section,
+// the auxiliary segments and the salts, and assumes every query opens distinct Merkle paths as the prover does.
+func EstimateProofSize(a air.AIR, params Parameters) (int, error) {
+	if err := params.Validate(); err != nil {
+		return 0, err
+	}
+
+	l, err := newLayout(a, params)
+	if err != nil {
+		return 0, err
+	}
+
+	return l.proofSize(params), nil
+}
+
+// targetLayout returns the layout of a proof of the target computation with the parameters
+func targetLayout(target SecurityTarget, params Parameters) *layout {
+	l := &layout{
+		traceLength:   target.TraceLength,
+		traceWidth:    target.TraceWidth,
+		auxWidths:     target.AuxiliaryWidths,
+		traceBound:    traceBound(target.TraceLength, int(params.NumQueries), params.ZeroKnowledge),
+		zeroKnowledge: params.ZeroKnowledge,
+	}
+
+	l.compositionColumns = compositionColumns(l.traceLength, l.traceBound, target.MaxConstraintDegree)
+
+	l.compositionWidth = l.compositionColumns
+	if params.ZeroKnowledge {
+		l.compositionWidth++
+	}
+
+	return l
+}
+
+// proofSize returns the size of an encoded proof with the layout, the sum of the sections of the proof shape
+func (l *layout) proofSize(params Parameters) int {
+	size := 0
+	for _, section := range l.proofShape(params).Sizes() {
+		size += section.Bytes
+	}
+
+	return size
+}
+
+// proofShape returns a proof with as many elements, digests and openings as the prover generates for the layout,
+// all of them zero
+func (l *layout) proofShape(params Parameters) *Proof {
+	depth := bits.Len(uint(l.traceLength*int(params.BlowupFactor))) - 1
+	folds := friFolds(l.traceBound, params.MaxRemainderDegree)
+
+	salts := 0
+	if l.zeroKnowledge {
+		salts = saltElements
+	}
+
+	opening := func(values, salts, depth int) Opening {
+		return Opening{
+			Values: make([]*math.PrimeField, values),
+			Salt:   make([]*math.PrimeField, salts),
+			Path:   make(merkle.Path, depth),
+		}
+	}
+
+	query := Query{
+		Trace:       opening(l.traceWidth, salts, depth),
+		Composition: opening(l.compositionWidth, salts, depth),
+	}
+
+	for _, width := range l.auxWidths {
+		query.Auxiliary = append(query.Auxiliary, opening(width, salts, depth))
+	}
+
+	for f := range folds {
+		query.FRI = append(query.FRI, opening(2, 0, depth-1-f))
+	}
+
+	queries := make([]Query, params.NumQueries)
+	for i := range queries {
+		queries[i] = query
+	}
+
+	return &Proof{
+		Parameters:     params,
+		AuxiliaryRoots: make([]merkle.Digest, len(l.auxWidths)),
+		OOD: OODFrame{
+			Current:     make([]*math.PrimeField, l.width()),
+			Next:        make([]*math.PrimeField, l.width()),
+			Composition: make([]*math.PrimeField, l.compositionWidth),
+		},
+		FRI: FRIProof{
+			Roots:     make([]merkle.Digest, folds),
+			Remainder: make([]*math.PrimeField, l.traceBound>>folds),
+		},
+		Queries: queries,
+	}
+}
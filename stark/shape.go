@@ -0,0 +1,92 @@
+package stark
+
+import (
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+)
+
+// Shape holds the constants a verifier of an AIR derives from the parameters before reading a proof.
+// Verifiers specialized to one AIR, like the contracts of package solidity, embed them instead of computing them.
+type Shape struct {
+	Parameters Parameters
+
+	TraceLength int
+	TraceWidth  int
+
+	// AuxiliaryWidths and AuxiliaryChallenges are the widths of the auxiliary segments and the numbers
+	// of challenges drawn before each of them
+	AuxiliaryWidths     []int
+	AuxiliaryChallenges []int
+
+	// DomainSize is the size N of the evaluation domain Offset·<Root>, TraceRoot generates the trace domain
+	DomainSize int
+	Offset     *math.PrimeField
+	Root       *math.PrimeField
+	TraceRoot  *math.PrimeField
+
+	// TraceBound is the degree bound of the committed trace polynomials
+	TraceBound int
+
+	// CompositionColumns are combined into the composition polynomial at z, CompositionWidth columns are committed
+	CompositionColumns int
+	CompositionWidth   int
+
+	// SaltElements is the number of salt elements of every opened row, 0 without zero-knowledge
+	SaltElements int
+
+	// FRIFolds is the number of committed FRI layers and RemainderSize the number of remainder coefficients
+	FRIFolds      int
+	RemainderSize int
+
+	// Fingerprint is the parameter fingerprint as the first element of the transcript seed
+	Fingerprint *math.PrimeField
+}
+
+// NewShape checks that the AIR can be proven with the parameters and returns the constants of its verifier
+func NewShape(a air.AIR, params Parameters) (*Shape, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	l, err := newLayout(a, params)
+	if err != nil {
+		return nil, err
+	}
+
+	d := newDomain(a.TraceLength(), int(params.BlowupFactor))
+	folds := friFolds(l.traceBound, params.MaxRemainderDegree)
+
+	s := &Shape{
+		Parameters:          params,
+		TraceLength:         l.traceLength,
+		TraceWidth:          l.traceWidth,
+		AuxiliaryWidths:     l.auxWidths,
+		AuxiliaryChallenges: l.challenges,
+		DomainSize:          d.size,
+		Offset:              d.offset,
+		Root:                d.root,
+		TraceRoot:           d.traceRoot,
+		TraceBound:          l.traceBound,
+		CompositionColumns:  l.compositionColumns,
+		CompositionWidth:    l.compositionWidth,
+		FRIFolds:            folds,
+		RemainderSize:       l.traceBound >> folds,
+		Fingerprint:         fingerprintElement(params),
+	}
+
+	if l.zeroKnowledge {
+		s.SaltElements = saltElements
+	}
+
+	return s, nil
+}
+
+// Width returns the number of columns of the main and auxiliary segments
+func (s *Shape) Width() int {
+	width := s.TraceWidth
+	for _, w := range s.AuxiliaryWidths {
+		width += w
+	}
+
+	return width
+}
@@ -0,0 +1,109 @@
+package stark
+
+import (
+	"encoding/binary"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// transcript is the Fiat-Shamir transcript shared by the prover and the verifier.
+// Absorbed data is chained into a single digest, challenges are derived from it with a counter.
+type transcript struct {
+	hasher  merkle.Hasher
+	state   merkle.Digest
+	counter uint64
+}
+
+// newTranscript seeds the transcript with the parameters and the public statement: the assertions,
+// the public inputs and the periodic columns of the AIR
+func newTranscript(hasher merkle.Hasher, params Parameters, a air.AIR) *transcript {
+	seed := []*math.PrimeField{
+		fingerprintElement(params),
+		math.NewPrimeField(int64(a.TraceLength())),
+		math.NewPrimeField(int64(a.TraceWidth())),
+	}
+
+	for _, assertion := range a.Assertions() {
+		seed = append(seed,
+			math.NewPrimeField(int64(assertion.Column)),
+			math.NewPrimeField(int64(assertion.Step)),
+			assertion.Value,
+		)
+	}
+
+	// Public inputs and periodic columns are prefixed with their length, AIRs without them keep the seed above
+	if public := air.PublicInputs(a); len(public) != 0 {
+		seed = append(seed, math.NewPrimeField(int64(len(public))))
+		seed = append(seed, public...)
+	}
+
+	for _, column := range air.Periodic(a) {
+		seed = append(seed, math.NewPrimeField(int64(column.Period())))
+		seed = append(seed, column...)
+	}
+
+	return &transcript{
+		hasher: hasher,
+		state:  hasher.HashElements(seed),
+	}
+}
+
+// fingerprintElement returns the parameter fingerprint read as a little-endian integer reduced modulo the field
+func fingerprintElement(params Parameters) *math.PrimeField {
+	fingerprint := params.Fingerprint()
+
+	return math.NewPrimeFieldUint64(binary.LittleEndian.Uint64(fingerprint[:]) % math.Modulus)
+}
+
+// absorbDigest mixes a commitment into the transcript
+func (t *transcript) absorbDigest(digest merkle.Digest) {
+	t.state = t.hasher.Merge(t.state, digest)
+	t.counter = 0
+}
+
+// absorbElements mixes field elements into the transcript
+func (t *transcript) absorbElements(elements []*math.PrimeField) {
+	t.absorbDigest(t.hasher.HashElements(elements))
+}
+
+// squeeze returns a fresh pseudo-random digest
+func (t *transcript) squeeze() merkle.Digest {
+	t.counter++
+	return t.hasher.Merge(t.state, t.hasher.HashElements([]*math.PrimeField{math.NewPrimeFieldUint64(t.counter)}))
+}
+
+// drawElement returns a pseudo-random field element, 64-bit values above the modulus are rejected
+func (t *transcript) drawElement() *math.PrimeField {
+	for {
+		digest := t.squeeze()
+
+		for i := 0; i+8 <= len(digest); i += 8 {
+			if value := binary.LittleEndian.Uint64(digest[i:]); value < math.Modulus {
+				return math.NewPrimeFieldUint64(value)
+			}
+		}
+	}
+}
+
+// drawElements returns n pseudo-random field elements
+func (t *transcript) drawElements(n int) []*math.PrimeField {
+	output := make([]*math.PrimeField, n)
+	for i := range output {
+		output[i] = t.drawElement()
+	}
+
+	return output
+}
+
+// drawIndices returns n pseudo-random indices below the power of two size
+func (t *transcript) drawIndices(n int, size int) []int {
+	output := make([]int, n)
+	for i := range output {
+		digest := t.squeeze()
+		output[i] = int(binary.LittleEndian.Uint64(digest[:]) & uint64(size-1))
+	}
+
+	return output
+}
@@ -0,0 +1,154 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// ErrVerification is returned when a proof is rejected
+var ErrVerification = errors.New("proof verification failed")
+
+// Verify checks a proof that a trace satisfying the AIR exists.
+// The proof must have been generated with the given parameters.
+func Verify(a air.AIR, proof *Proof, params Parameters) error {
+	if err := verify(a, proof, params); err != nil {
+		return fmt.Errorf("%w: %w", ErrVerification, err)
+	}
+
+	return nil
+}
+
+func verify(a air.AIR, proof *Proof, params Parameters) error {
+	if proof.Parameters != params {
+		return fmt.Errorf("proof parameters %+v do not match %+v", proof.Parameters, params)
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	l, err := newLayout(a, params)
+	if err != nil {
+		return err
+	}
+
+	if int(proof.TraceLength) != a.TraceLength() || int(proof.TraceWidth) != a.TraceWidth() {
+		return fmt.Errorf("proof is for a %dx%d trace, expected %dx%d", proof.TraceLength, proof.TraceWidth, a.TraceLength(), a.TraceWidth())
+	}
+
+	hasher, _ := params.Hash.Hasher()
+	t := newTranscript(hasher, params, a)
+	d := newDomain(a.TraceLength(), int(params.BlowupFactor))
+
+	t.absorbDigest(proof.TraceRoot)
+
+	segments := len(l.auxWidths)
+	if len(proof.AuxiliaryRoots) != segments {
+		return fmt.Errorf("expected %d auxiliary commitments, got %d", segments, len(proof.AuxiliaryRoots))
+	}
+
+	challenges := make([][]*math.PrimeField, segments)
+	for k, root := range proof.AuxiliaryRoots {
+		challenges[k] = t.drawElements(l.challenges[k])
+		t.absorbDigest(root)
+	}
+
+	c, err := newConstraints(a, challenges)
+	if err != nil {
+		return err
+	}
+
+	coefficients := drawCompositionCoefficients(t, c)
+	t.absorbDigest(proof.CompositionRoot)
+
+	// Out-of-domain consistency: the composition columns at z must match the constraints evaluated on the trace at z
+	z := drawOODPoint(t, d)
+	zNext := new(math.PrimeField).Mul(z, d.traceRoot)
+
+	ood := &proof.OOD
+	if len(ood.Current) != l.width() || len(ood.Next) != l.width() || len(ood.Composition) != l.compositionWidth {
+		return fmt.Errorf("out-of-domain frame has wrong shape")
+	}
+
+	expected := evaluateComposition(c, d, coefficients, z, ood.Current, ood.Next)
+	if !combineCompositionColumns(d, z, ood.Composition[:l.compositionColumns]).Equals(expected) {
+		return fmt.Errorf("out-of-domain composition does not match the constraints")
+	}
+
+	t.absorbElements(ood.elements())
+
+	deep := drawDeepCoefficients(t, l.width(), l.compositionWidth)
+
+	fri, err := newFriVerifier(t, &proof.FRI, l.traceBound, params)
+	if err != nil {
+		return err
+	}
+
+	if !t.checkProofOfWork(proof.PowNonce, params.GrindingBits) {
+		return fmt.Errorf("proof-of-work nonce does not have %d leading zero bits", params.GrindingBits)
+	}
+	t.absorbNonce(proof.PowNonce)
+
+	indices := t.drawIndices(int(params.NumQueries), d.size)
+	if len(proof.Queries) != len(indices) {
+		return fmt.Errorf("expected %d queries, got %d", len(indices), len(proof.Queries))
+	}
+
+	for q, index := range indices {
+		query := &proof.Queries[q]
+
+		if int(query.Index) != index {
+			return fmt.Errorf("query %d has index %d, expected %d", q, query.Index, index)
+		}
+
+		if err = verifyOpening(hasher, l, d.depth(), proof.TraceRoot, index, query.Trace, a.TraceWidth()); err != nil {
+			return fmt.Errorf("query %d trace: %w", q, err)
+		}
+
+		if len(query.Auxiliary) != segments {
+			return fmt.Errorf("query %d has %d auxiliary rows, expected %d", q, len(query.Auxiliary), segments)
+		}
+
+		values := query.Trace.Values
+		for k, opening := range query.Auxiliary {
+			if err = verifyOpening(hasher, l, d.depth(), proof.AuxiliaryRoots[k], index, opening, l.auxWidths[k]); err != nil {
+				return fmt.Errorf("query %d auxiliary segment %d: %w", q, k+1, err)
+			}
+
+			values = append(append([]*math.PrimeField{}, values...), opening.Values...)
+		}
+
+		if err = verifyOpening(hasher, l, d.depth(), proof.CompositionRoot, index, query.Composition, l.compositionWidth); err != nil {
+			return fmt.Errorf("query %d composition: %w", q, err)
+		}
+
+		value := evaluateDeep(deep, ood, z, zNext, d.points[index], values, query.Composition.Values)
+
+		if err = fri.verifyQuery(hasher, d, &proof.FRI, index, value, query.FRI); err != nil {
+			return fmt.Errorf("query %d: %w", q, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyOpening checks that the opened row has the expected width and is committed at index in a tree of the given depth
+func verifyOpening(hasher merkle.Hasher, l *layout, depth int, root merkle.Digest, index int, opening Opening, width int) error {
+	if len(opening.Values) != width {
+		return fmt.Errorf("opened row has width %d, expected %d", len(opening.Values), width)
+	}
+
+	if l.zeroKnowledge && len(opening.Salt) != saltElements || !l.zeroKnowledge && len(opening.Salt) != 0 {
+		return fmt.Errorf("opened row has %d salt elements", len(opening.Salt))
+	}
+
+	if !merkle.Verify(hasher, root, depth, index, hashRow(hasher, opening.Values, opening.Salt), opening.Path) {
+		return fmt.Errorf("invalid Merkle path")
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package stark
+
+import (
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+)
+
+// randomElement returns a uniformly random field element
+func randomElement() *math.PrimeField {
+	element := new(math.PrimeField)
+	if _, err := element.SetRandom(); err != nil {
+		panic("stark: cannot read randomness: " + err.Error())
+	}
+
+	return element
+}
+
+// randomPolynom returns a polynomial with degreeBound uniformly random coefficients
+func randomPolynom(degreeBound int) *math.Polynom {
+	coefficients := make([]*math.PrimeField, degreeBound)
+	for i := range coefficients {
+		coefficients[i] = randomElement()
+	}
+
+	return math.NewPolynom(coefficients)
+}
+
+// maskTrace returns T(x) + (x^n - 1)·r(x) for a random r of degree below the mask degree.
+// The masked polynomial takes the same values on the trace domain, while any maskDegree
+// evaluations outside of it are uniformly random.
+func maskTrace(column *math.Polynom, l *layout) *math.Polynom {
+	mask := randomPolynom(l.maskDegree)
+
+	coefficients := make([]*math.PrimeField, l.traceLength+l.maskDegree)
+	for i := range coefficients {
+		coefficients[i] = new(math.PrimeField).SetZero()
+		if i < column.Len() {
+			coefficients[i] = column.At(i).Copy()
+		}
+	}
+
+	for i, r := range mask.Coefficients {
+		coefficients[i] = new(math.PrimeField).Sub(coefficients[i], r)
+		coefficients[i+l.traceLength] = new(math.PrimeField).Add(coefficients[i+l.traceLength], r)
+	}
+
+	return math.NewPolynom(coefficients)
+}
+
+// maskComposition adds x^n·b_k(x) to part k and subtracts b_k(x) from part k+1 for random b_k of degree below
+// the mask degree. The composition Σ x^{kn}·C_k(x) is unchanged, while the parts opened at z and at the queries
+// no longer reveal how the composition polynomial splits into its coefficient ranges.
+func maskComposition(parts []*math.Polynom, l *layout) {
+	for k := 0; k+1 < l.compositionColumns; k++ {
+		mask := randomPolynom(l.maskDegree)
+
+		shifted := make([]*math.PrimeField, l.traceLength, l.traceLength+l.maskDegree)
+		for i := range shifted {
+			shifted[i] = new(math.PrimeField).SetZero()
+		}
+		shifted = append(shifted, mask.Coefficients...)
+
+		parts[k] = new(math.Polynom).Add(parts[k], math.NewPolynom(shifted))
+		parts[k+1] = new(math.Polynom).Sub(parts[k+1], mask)
+	}
+}
+
+// drawSalts returns random salts for every committed row in zero-knowledge mode and nil otherwise
+func drawSalts(l *layout, size int) [][]*math.PrimeField {
+	if !l.zeroKnowledge {
+		return nil
+	}
+
+	salts := make([][]*math.PrimeField, size)
+	for i := range salts {
+		salts[i] = make([]*math.PrimeField, saltElements)
+		for j := range salts[i] {
+			salts[i][j] = randomElement()
+		}
+	}
+
+	return salts
+}
+
+// salt returns the salt of the row at index, nil if rows are not salted
+func salt(salts [][]*math.PrimeField, index int) []*math.PrimeField {
+	if salts == nil {
+		return nil
+	}
+
+	return salts[index]
+}
+
+// hashRow hashes a committed row together with its salt
+func hashRow(hasher merkle.Hasher, values, salt []*math.PrimeField) merkle.Digest {
+	return hasher.HashElements(append(append([]*math.PrimeField{}, values...), salt...))
+}
@@ -0,0 +1,254 @@
+package tests
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func batchReceipts() [][]*math.PrimeField {
+	return [][]*math.PrimeField{
+		receiptPrices(3),
+		receiptPrices(5),
+		{},
+		receiptPrices(2),
+	}
+}
+
+func TestComputeBatch(t *testing.T) {
+	receipts := batchReceipts()
+
+	batch, err := air.ComputeBatch(receipts)
+	if err != nil {
+		t.Fatalf("ComputeBatch failed: %v", err)
+	}
+
+	if len(batch.Totals) != len(receipts) {
+		t.Fatalf("Expected %d totals, got %d", len(receipts), len(batch.Totals))
+	}
+
+	for k, prices := range receipts {
+		total := air.Compute(prices).Second[len(prices)]
+		if !batch.Totals[k].Equals(total) {
+			t.Errorf("Receipt %d total is %v, expected %v", k, batch.Totals[k], total)
+		}
+	}
+
+	n := len(batch.First)
+	if n&(n-1) != 0 {
+		t.Errorf("Trace length %d is not a power of two", n)
+	}
+
+	if err = air.Check(batch.AIR(), batch.Trace()); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+
+	if _, err = air.ComputeBatch(nil); err == nil {
+		t.Errorf("ComputeBatch accepted an empty batch")
+	}
+}
+
+func TestBatchRejectsWrongTotals(t *testing.T) {
+	batch, err := air.ComputeBatch(batchReceipts())
+	if err != nil {
+		t.Fatalf("ComputeBatch failed: %v", err)
+	}
+
+	trace := batch.Trace()
+	statement := batch.AIR()
+	challenges := segmentChallenges(t, statement)
+
+	if _, err = air.BuildSegments(statement, trace, challenges); err != nil {
+		t.Fatalf("BuildSegments failed: %v", err)
+	}
+
+	wrong := func(totals []*math.PrimeField) *air.BatchAIR {
+		return &air.BatchAIR{Steps: statement.Steps, Totals: totals}
+	}
+
+	changed := append([]*math.PrimeField{}, batch.Totals...)
+	changed[1] = new(math.PrimeField).Add(changed[1], math.NewPrimeField(1))
+
+	swapped := append([]*math.PrimeField{}, batch.Totals...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	// A leading empty receipt would not change a plain fold of the totals
+	extra := append([]*math.PrimeField{new(math.PrimeField).SetZero()}, batch.Totals...)
+
+	for name, totals := range map[string][]*math.PrimeField{
+		"changed": changed,
+		"swapped": swapped,
+		"extra":   extra,
+		"missing": batch.Totals[1:],
+	} {
+		if _, err = air.BuildSegments(wrong(totals), trace, challenges); err == nil {
+			t.Errorf("BuildSegments accepted %s totals", name)
+		}
+	}
+
+	// A boundary that does not hold the running total
+	trace[3].Coefficients[0] = new(math.PrimeField).Add(trace[3].At(0), math.NewPrimeField(1))
+	if err = air.Check(statement, trace); err == nil {
+		t.Errorf("Check accepted a boundary row that is not the total")
+	}
+}
+
+func TestBatchProveVerify(t *testing.T) {
+	receipts := make([][]*math.PrimeField, 20)
+	for k := range receipts {
+		receipts[k] = receiptPrices(k%7 + 1)
+	}
+
+	batch, err := air.ComputeBatch(receipts)
+	if err != nil {
+		t.Fatalf("ComputeBatch failed: %v", err)
+	}
+
+	statement := batch.AIR()
+	params := stark.DefaultParameters()
+
+	proof, err := stark.Prove(statement, batch.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	totals := append([]*math.PrimeField{}, batch.Totals...)
+	totals[7] = new(math.PrimeField).Add(totals[7], math.NewPrimeField(1))
+
+	if err = stark.Verify(&air.BatchAIR{Steps: statement.Steps, Totals: totals}, proof, params); err == nil {
+		t.Errorf("Verify accepted a wrong total")
+	}
+}
+
+// replayTranscript follows the Fiat-Shamir transcript of package stark from the outside: the state is the hash
+// of the statement chained with every absorbed digest, and drawing challenges does not change it
+type replayTranscript struct {
+	hasher  merkle.Hasher
+	state   merkle.Digest
+	counter uint64
+}
+
+func newReplayTranscript(t *testing.T, params stark.Parameters, a air.AIR) *replayTranscript {
+	hasher, err := params.Hash.Hasher()
+	if err != nil {
+		t.Fatalf("Hasher failed: %v", err)
+	}
+
+	fingerprint := params.Fingerprint()
+	seed := []*math.PrimeField{
+		math.NewPrimeFieldUint64(binary.LittleEndian.Uint64(fingerprint[:]) % math.Modulus),
+		math.NewPrimeField(int64(a.TraceLength())),
+		math.NewPrimeField(int64(a.TraceWidth())),
+	}
+
+	for _, assertion := range a.Assertions() {
+		seed = append(seed, math.NewPrimeField(int64(assertion.Column)), math.NewPrimeField(int64(assertion.Step)), assertion.Value)
+	}
+
+	if public := air.PublicInputs(a); len(public) != 0 {
+		seed = append(append(seed, math.NewPrimeField(int64(len(public)))), public...)
+	}
+
+	return &replayTranscript{hasher: hasher, state: hasher.HashElements(seed)}
+}
+
+func (r *replayTranscript) absorb(digest merkle.Digest) {
+	r.state = r.hasher.Merge(r.state, digest)
+	r.counter = 0
+}
+
+func (r *replayTranscript) draw() *math.PrimeField {
+	for {
+		r.counter++
+		digest := r.hasher.Merge(r.state, r.hasher.HashElements([]*math.PrimeField{math.NewPrimeFieldUint64(r.counter)}))
+
+		for i := 0; i+8 <= len(digest); i += 8 {
+			if value := binary.LittleEndian.Uint64(digest[i:]); value < math.Modulus {
+				return math.NewPrimeFieldUint64(value)
+			}
+		}
+	}
+}
+
+// index draws a query position below the power of two size like the transcript of package stark
+func (r *replayTranscript) index(size int) int {
+	r.counter++
+	digest := r.hasher.Merge(r.state, r.hasher.HashElements([]*math.PrimeField{math.NewPrimeFieldUint64(r.counter)}))
+
+	return int(binary.LittleEndian.Uint64(digest[:]) & uint64(size-1))
+}
+
+// foldTotals is the value H = Σ γ^(K-2-k)·(β - t_k) the auxiliary segment of BatchAIR ends with
+func foldTotals(totals []*math.PrimeField, gamma, beta *math.PrimeField) *math.PrimeField {
+	h := new(math.PrimeField).SetZero()
+	for _, total := range totals[:len(totals)-1] {
+		h = new(math.PrimeField).Add(new(math.PrimeField).Mul(gamma, h), new(math.PrimeField).Sub(beta, total))
+	}
+
+	return h
+}
+
+// TestBatchRejectsForgedTotals changes two totals so that the fold with the challenges of the proof is unchanged,
+// which a verifier accepts unless the totals are bound before the challenges are drawn
+func TestBatchRejectsForgedTotals(t *testing.T) {
+	batch, err := air.ComputeBatch(batchReceipts())
+	if err != nil {
+		t.Fatalf("ComputeBatch failed: %v", err)
+	}
+
+	statement := batch.AIR()
+	params := stark.DefaultParameters()
+
+	proof, err := stark.Prove(statement, batch.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	replay := newReplayTranscript(t, params, statement)
+	replay.absorb(proof.TraceRoot)
+	gamma, beta := replay.draw(), replay.draw()
+
+	// The replay must reach the query positions of the proof, which are drawn after every remaining commitment
+	replay.absorb(proof.AuxiliaryRoots[0])
+	replay.absorb(proof.CompositionRoot)
+
+	ood := append(append(append([]*math.PrimeField{}, proof.OOD.Current...), proof.OOD.Next...), proof.OOD.Composition...)
+	replay.absorb(replay.hasher.HashElements(ood))
+
+	for _, root := range proof.FRI.Roots {
+		replay.absorb(root)
+	}
+
+	replay.absorb(replay.hasher.HashElements(proof.FRI.Remainder))
+	replay.absorb(replay.hasher.HashElements([]*math.PrimeField{
+		math.NewPrimeFieldUint64(proof.PowNonce & 0xffffffff),
+		math.NewPrimeFieldUint64(proof.PowNonce >> 32),
+	}))
+
+	for q, query := range proof.Queries {
+		if replay.index(statement.TraceLength()*int(params.BlowupFactor)) != int(query.Index) {
+			t.Fatalf("Replayed transcript does not match the prover at query %d", q)
+		}
+	}
+
+	// t1 + 1 and t2 - γ keep γ²·(β - t0) + γ·(β - t1) + (β - t2)
+	forged := append([]*math.PrimeField{}, batch.Totals...)
+	forged[1] = new(math.PrimeField).Add(forged[1], math.NewPrimeField(1))
+	forged[2] = new(math.PrimeField).Sub(forged[2], gamma)
+
+	if !foldTotals(forged, gamma, beta).Equals(foldTotals(batch.Totals, gamma, beta)) {
+		t.Fatalf("Forged totals do not keep the fold")
+	}
+
+	if err = stark.Verify(&air.BatchAIR{Steps: statement.Steps, Totals: forged}, proof, params); err == nil {
+		t.Errorf("Verify accepted totals forged with the challenges of the proof")
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func testCatalog(t *testing.T) *air.Catalog {
+	entries := make([]air.CatalogEntry, 5)
+	for i := range entries {
+		entries[i] = air.CatalogEntry{SKU: math.NewPrimeField(int64(1000 + i)), Price: math.NewPrimeField(int64(199 + 100*i))}
+	}
+
+	catalog, err := air.NewCatalog(entries)
+	if err != nil {
+		t.Fatalf("NewCatalog failed: %v", err)
+	}
+
+	return catalog
+}
+
+func catalogLines() []air.CatalogLine {
+	return []air.CatalogLine{
+		{SKU: math.NewPrimeField(1003), Quantity: math.NewPrimeField(2)},
+		{SKU: math.NewPrimeField(1000), Quantity: math.NewPrimeField(1)},
+		{SKU: math.NewPrimeField(1004), Quantity: math.NewPrimeField(3)},
+	}
+}
+
+func TestRescueHasherMerkleTree(t *testing.T) {
+	leaves := make([]merkle.Digest, 4)
+	for i := range leaves {
+		leaves[i] = rescue.Hasher{}.HashElements([]*math.PrimeField{math.NewPrimeField(int64(i))})
+	}
+
+	tree, err := merkle.NewTree(rescue.Hasher{}, leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+
+	for i, leaf := range leaves {
+		if !merkle.Verify(rescue.Hasher{}, tree.Root(), 2, i, leaf, tree.Open(i)) {
+			t.Errorf("Verify failed for leaf %d", i)
+		}
+	}
+
+	state := rescue.NodeState()
+	copy(state, append(merkle.DigestToElements(leaves[0]), merkle.DigestToElements(leaves[1])...))
+	if (rescue.Hasher{}).Merge(leaves[0], leaves[1]) != merkle.ElementsToDigest(rescue.Permute(state)[:rescue.DigestElements]) {
+		t.Errorf("Merge is not the permutation of both digests in the node state")
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := testCatalog(t)
+
+	if len(catalog.Entries) != 8 || catalog.Depth() != 3 {
+		t.Errorf("Expected 8 padded entries of depth 3, got %d of depth %d", len(catalog.Entries), catalog.Depth())
+	}
+
+	if _, ok := catalog.Lookup(math.NewPrimeField(999)); ok {
+		t.Errorf("Lookup found an unknown SKU")
+	}
+
+	duplicate := []air.CatalogEntry{catalog.Entries[0], catalog.Entries[0]}
+	if _, err := air.NewCatalog(duplicate); err == nil {
+		t.Errorf("NewCatalog accepted a duplicate SKU")
+	}
+
+	if _, err := air.ComputeCatalogReceipt(catalog, []air.CatalogLine{{SKU: math.NewPrimeField(1), Quantity: math.NewPrimeField(1)}}); err == nil {
+		t.Errorf("ComputeCatalogReceipt accepted an unknown SKU")
+	}
+}
+
+func TestCatalogReceipt(t *testing.T) {
+	catalog := testCatalog(t)
+
+	receipt, err := air.ComputeCatalogReceipt(catalog, catalogLines())
+	if err != nil {
+		t.Fatalf("ComputeCatalogReceipt failed: %v", err)
+	}
+
+	if !receipt.Receipt.Total().Equals(math.NewPrimeField(2*499 + 199 + 3*599)) {
+		t.Errorf("Unexpected grand total %v", receipt.Receipt.Total())
+	}
+
+	statement := receipt.AIR()
+	if err = air.Check(statement, receipt.Trace()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	// An invented price with a consistent receipt does not match the catalog
+	receipt.Receipt.UnitPrices[0] = math.NewPrimeField(1)
+	receipt.Receipt.LineTotals[0] = new(math.PrimeField).Mul(receipt.Receipt.Quantities[0], receipt.Receipt.UnitPrices[0])
+	for i := 1; i < len(receipt.Receipt.Sums); i++ {
+		receipt.Receipt.Sums[i] = new(math.PrimeField).Add(receipt.Receipt.Sums[i-1], receipt.Receipt.LineTotals[i-1])
+	}
+
+	invented := receipt.AIR()
+	if err = air.Check(invented, receipt.Trace()); err == nil {
+		t.Errorf("Check accepted a price that is not in the catalog")
+	}
+
+	other := *statement
+	other.Root[0] ^= 1
+	if err = air.Check(&other, receipt.Trace()); err == nil {
+		t.Errorf("Check accepted a different catalog root")
+	}
+}
+
+func TestCatalogProveVerify(t *testing.T) {
+	params := stark.DefaultParameters()
+	params.NumQueries = 16
+
+	receipt, err := air.ComputeCatalogReceipt(testCatalog(t), catalogLines())
+	if err != nil {
+		t.Fatalf("ComputeCatalogReceipt failed: %v", err)
+	}
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	statement := receipt.AIR()
+	statement.Root = testCatalog(t).Root()
+	statement.Root[1] ^= 1
+	if err = stark.Verify(statement, proof, params); err == nil {
+		t.Errorf("Verify accepted a different catalog root")
+	}
+}
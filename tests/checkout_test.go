@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestComputeCheckout(t *testing.T) {
+	// subtotal 1554
+	items := lineItems()
+
+	tests := []struct {
+		rules                          air.PricingRules
+		subtotal, discount, tax, total int64
+	}{
+		{air.PricingRules{}, 1554, 0, 0, 1554},
+		// 10% of 1554 is 155.4, rounded down
+		{air.PricingRules{DiscountBasisPoints: 1000}, 1554, 155, 0, 1399},
+		// 8.25% of 1554 - 200 is 111.705, rounded half up
+		{air.PricingRules{Coupon: 200, TaxBasisPoints: 825}, 1554, 200, 112, 1466},
+		// 5% of 1554 is 77.7, 7.5% of 1554 - 77 - 50 is 107.025
+		{air.PricingRules{DiscountBasisPoints: 500, Coupon: 50, TaxBasisPoints: 750}, 1554, 127, 107, 1534},
+		// 2% of 1250 is exactly 25, rounding half up
+		{air.PricingRules{Coupon: 304, TaxBasisPoints: 200}, 1554, 304, 25, 1275},
+		{air.PricingRules{DiscountBasisPoints: 10000}, 1554, 1554, 0, 0},
+	}
+
+	for _, tt := range tests {
+		checkout, err := air.ComputeCheckout(items, tt.rules)
+		if err != nil {
+			t.Fatalf("ComputeCheckout(%+v) failed: %v", tt.rules, err)
+		}
+
+		expected := air.Summary{
+			Subtotal: math.NewPrimeField(tt.subtotal),
+			Discount: math.NewPrimeField(tt.discount),
+			Tax:      math.NewPrimeField(tt.tax),
+			Total:    math.NewPrimeField(tt.total),
+		}
+
+		got := checkout.Summary
+		if !got.Subtotal.Equals(expected.Subtotal) || !got.Discount.Equals(expected.Discount) ||
+			!got.Tax.Equals(expected.Tax) || !got.Total.Equals(expected.Total) {
+			t.Errorf("ComputeCheckout(%+v): expected %v, got %v", tt.rules, expected, got)
+		}
+
+		if err = air.Check(checkout.AIR(), checkout.Trace()); err != nil {
+			t.Errorf("Check(%+v) failed: %v", tt.rules, err)
+		}
+	}
+}
+
+func TestComputeCheckoutRejectsInvalidRules(t *testing.T) {
+	for _, rules := range []air.PricingRules{
+		{DiscountBasisPoints: 10001},
+		{TaxBasisPoints: 20000},
+		{Coupon: 1555},
+		{DiscountBasisPoints: 9000, Coupon: 200},
+	} {
+		if _, err := air.ComputeCheckout(lineItems(), rules); err == nil {
+			t.Errorf("ComputeCheckout accepted %+v", rules)
+		}
+	}
+
+	// A subtotal of 2^32 - 2 with 100% tax does not fit the total in 32 bits
+	large := []air.LineItem{air.NewLineItem(1, 1<<31-1), air.NewLineItem(1, 1<<31-1)}
+	if _, err := air.ComputeCheckout(large, air.PricingRules{TaxBasisPoints: 10000}); err == nil {
+		t.Errorf("ComputeCheckout accepted a total above %d bits", air.RangeBits)
+	}
+}
+
+// TestCheckoutAIRRejectsShiftedRemainder raises the tax remainder by one and lowers the tax and the total
+// by 10000^-1, which keeps 10000·tax + remainder and every remainder check satisfied,
+// so only the range checks of the tax and the total reject the summary
+func TestCheckoutAIRRejectsShiftedRemainder(t *testing.T) {
+	checkout, err := air.ComputeCheckout(lineItems(), air.PricingRules{Coupon: 200, TaxBasisPoints: 825})
+	if err != nil {
+		t.Fatalf("ComputeCheckout failed: %v", err)
+	}
+
+	// The last assertions bind the tax and the total, the tax is followed by its remainder and gap
+	assertions := checkout.AIR().Assertions()
+	taxColumn, totalColumn := assertions[len(assertions)-2].Column, assertions[len(assertions)-1].Column
+
+	trace := checkout.Trace()
+	remainder, gap := trace[0].At(taxColumn+1), trace[0].At(taxColumn+2)
+
+	check := air.RangeCheck{Bits: 14}
+	remainderBits := findBits(trace[0].Coefficients, totalColumn+1, check.Decompose(remainder))
+	gapBits := findBits(trace[0].Coefficients, totalColumn+1, check.Decompose(gap))
+	if remainderBits < 0 || gapBits < 0 {
+		t.Fatalf("Remainder decompositions not found in the trace")
+	}
+
+	one := math.NewPrimeField(1)
+	shift := new(math.PrimeField).Inv(math.NewPrimeField(air.BasisPoints))
+
+	tax := new(math.PrimeField).Sub(checkout.Summary.Tax, shift)
+	total := new(math.PrimeField).Sub(checkout.Summary.Total, shift)
+	remainder = new(math.PrimeField).Add(remainder, one)
+	gap = new(math.PrimeField).Sub(gap, one)
+
+	for _, row := range trace {
+		row.Coefficients[taxColumn] = tax
+		row.Coefficients[taxColumn+1] = remainder
+		row.Coefficients[taxColumn+2] = gap
+		row.Coefficients[totalColumn] = total
+		copy(row.Coefficients[remainderBits:], check.Decompose(remainder))
+		copy(row.Coefficients[gapBits:], check.Decompose(gap))
+	}
+
+	statement := checkout.AIR()
+	statement.Summary.Tax = tax
+	statement.Summary.Total = total
+
+	if err = air.Check(statement, trace); err == nil {
+		t.Errorf("Check accepted tax %d and total %d", tax.Uint64(), total.Uint64())
+	}
+}
+
+// findBits returns the first column from which the row holds the bits, or -1
+func findBits(row []*math.PrimeField, from int, bits []*math.PrimeField) int {
+	for offset := from; offset+len(bits) <= len(row); offset++ {
+		if math.NewPolynom(row[offset : offset+len(bits)]).Equals(math.NewPolynom(bits)) {
+			return offset
+		}
+	}
+
+	return -1
+}
+
+func TestCheckoutAIRRejectsWrongSummary(t *testing.T) {
+	checkout, err := air.ComputeCheckout(lineItems(), air.PricingRules{DiscountBasisPoints: 1000, TaxBasisPoints: 825})
+	if err != nil {
+		t.Fatalf("ComputeCheckout failed: %v", err)
+	}
+
+	one := math.NewPrimeField(1)
+	for _, tamper := range []func(s *air.Summary){
+		func(s *air.Summary) { s.Subtotal = new(math.PrimeField).Add(s.Subtotal, one) },
+		func(s *air.Summary) { s.Discount = new(math.PrimeField).Add(s.Discount, one) },
+		func(s *air.Summary) { s.Tax = new(math.PrimeField).Sub(s.Tax, one) },
+		func(s *air.Summary) { s.Total = new(math.PrimeField).Add(s.Total, one) },
+	} {
+		statement := checkout.AIR()
+		tamper(&statement.Summary)
+
+		if err = air.Check(statement, checkout.Trace()); err == nil {
+			t.Errorf("Check accepted a wrong summary %v", statement.Summary)
+		}
+	}
+
+	// The trace is bound to the public pricing rules
+	statement := checkout.AIR()
+	statement.Rules.TaxBasisPoints++
+	if err = air.Check(statement, checkout.Trace()); err == nil {
+		t.Errorf("Check accepted different pricing rules")
+	}
+}
+
+func TestCheckoutProveVerify(t *testing.T) {
+	params := stark.DefaultParameters()
+
+	checkout, err := air.ComputeCheckout(lineItems(), air.PricingRules{DiscountBasisPoints: 500, Coupon: 50, TaxBasisPoints: 750})
+	if err != nil {
+		t.Fatalf("ComputeCheckout failed: %v", err)
+	}
+
+	proof, err := stark.Prove(checkout.AIR(), checkout.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(checkout.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	statement := checkout.AIR()
+	statement.Summary.Tax = math.NewPrimeField(0)
+	if err = stark.Verify(statement, proof, params); err == nil {
+		t.Errorf("Verify accepted a wrong tax")
+	}
+}
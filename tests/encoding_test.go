@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+)
+
+func TestPrimeFieldMarshalBinary(t *testing.T) {
+	a := math.NewPrimeField(-1)
+
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	if binary.LittleEndian.Uint64(data) != math.Modulus-1 || len(data) != math.PrimeFieldBytes {
+		t.Errorf("MarshalBinary is not canonical little-endian, got %x", data)
+	}
+
+	var b math.PrimeField
+	if err = b.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if !a.Equals(&b) {
+		t.Errorf("Binary round trip failed. Expected %v, got %v", a, &b)
+	}
+}
+
+func TestPrimeFieldRejectsNonCanonical(t *testing.T) {
+	var a math.PrimeField
+
+	if err := a.UnmarshalBinary(binary.LittleEndian.AppendUint64(nil, math.Modulus)); !errors.Is(err, math.ErrNonCanonical) {
+		t.Errorf("UnmarshalBinary should reject the modulus, got %v", err)
+	}
+
+	if err := a.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Errorf("UnmarshalBinary should reject short input")
+	}
+
+	if err := a.UnmarshalText([]byte("18446744069414584321")); !errors.Is(err, math.ErrNonCanonical) {
+		t.Errorf("UnmarshalText should reject the modulus, got %v", err)
+	}
+
+	if err := a.UnmarshalText([]byte("-1")); err == nil {
+		t.Errorf("UnmarshalText should reject negative values")
+	}
+
+	if err := json.Unmarshal([]byte(`5`), &a); err == nil {
+		t.Errorf("UnmarshalJSON should reject numbers")
+	}
+}
+
+func TestPrimeFieldMarshalJSON(t *testing.T) {
+	a := math.NewPrimeField(-3)
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	if string(data) != `"18446744069414584318"` {
+		t.Errorf("MarshalJSON is not canonical, got %s", data)
+	}
+
+	var b math.PrimeField
+	if err = json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+
+	if !a.Equals(&b) {
+		t.Errorf("JSON round trip failed. Expected %v, got %v", a, &b)
+	}
+}
+
+func TestPolynomMarshal(t *testing.T) {
+	poly := math.NewPolynom([]*math.PrimeField{
+		math.NewPrimeField(5),
+		math.NewPrimeField(-3),
+		math.NewPrimeField(2),
+	})
+
+	data, _ := poly.MarshalBinary()
+	if len(data) != 4+3*math.PrimeFieldBytes {
+		t.Errorf("MarshalBinary has unexpected length %d", len(data))
+	}
+
+	var decoded math.Polynom
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if !decoded.Equals(poly) {
+		t.Errorf("Binary round trip failed. Expected %v, got %v", poly, &decoded)
+	}
+
+	if err := decoded.UnmarshalBinary(append(data, 0)); !errors.Is(err, math.ErrTrailingBytes) {
+		t.Errorf("UnmarshalBinary should reject trailing bytes, got %v", err)
+	}
+
+	text, _ := poly.MarshalText()
+	if err := decoded.UnmarshalText(text); err != nil || !decoded.Equals(poly) {
+		t.Errorf("Text round trip failed. Expected %v, got %v (%v)", poly, &decoded, err)
+	}
+
+	jsonData, err := json.Marshal(poly)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	if string(jsonData) != `["5","18446744069414584318","2"]` {
+		t.Errorf("MarshalJSON failed, got %s", jsonData)
+	}
+
+	if err = json.Unmarshal(jsonData, &decoded); err != nil || !decoded.Equals(poly) {
+		t.Errorf("JSON round trip failed. Expected %v, got %v (%v)", poly, &decoded, err)
+	}
+}
+
+func TestReceiptMarshal(t *testing.T) {
+	receipt := air.Compute([]*math.PrimeField{
+		math.NewPrimeField(5),
+		math.NewPrimeField(12),
+		math.NewPrimeField(13),
+	})
+
+	equal := func(a, b *air.Receipt) bool {
+		return math.NewPolynom(a.First).Equals(math.NewPolynom(b.First)) &&
+			math.NewPolynom(a.Second).Equals(math.NewPolynom(b.Second))
+	}
+
+	data, _ := receipt.MarshalBinary()
+
+	var decoded air.Receipt
+	if err := decoded.UnmarshalBinary(data); err != nil || !equal(receipt, &decoded) {
+		t.Errorf("Binary round trip failed: %v", err)
+	}
+
+	if err := decoded.UnmarshalBinary(append(data, 0)); !errors.Is(err, math.ErrTrailingBytes) {
+		t.Errorf("UnmarshalBinary should reject trailing bytes, got %v", err)
+	}
+
+	text, _ := receipt.MarshalText()
+	if err := decoded.UnmarshalText(text); err != nil || !equal(receipt, &decoded) {
+		t.Errorf("Text round trip failed: %v", err)
+	}
+
+	jsonData, err := json.Marshal(receipt)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	if string(jsonData) != `{"first":["5","12","13","30"],"second":["0","5","17","30"]}` {
+		t.Errorf("MarshalJSON failed, got %s", jsonData)
+	}
+
+	if err = json.Unmarshal(jsonData, &decoded); err != nil || !equal(receipt, &decoded) {
+		t.Errorf("JSON round trip failed: %v", err)
+	}
+
+	if err = json.Unmarshal([]byte(`{"first":["1"],"second":[]}`), &decoded); err == nil {
+		t.Errorf("UnmarshalJSON should reject columns of different length")
+	}
+}
+
+func TestExecutionTraceMarshal(t *testing.T) {
+	trace := air.Compute([]*math.PrimeField{
+		math.NewPrimeField(5),
+		math.NewPrimeField(12),
+		math.NewPrimeField(13),
+	}).Trace()
+
+	equal := func(a, b air.ExecutionTrace) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !a[i].Equals(b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	data, err := trace.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	if len(data) != 8+4*2*math.PrimeFieldBytes {
+		t.Errorf("MarshalBinary has unexpected length %d", len(data))
+	}
+
+	var decoded air.ExecutionTrace
+	if err = decoded.UnmarshalBinary(data); err != nil || !equal(trace, decoded) {
+		t.Errorf("Binary round trip failed: %v", err)
+	}
+
+	text, _ := trace.MarshalText()
+	if err = decoded.UnmarshalText(text); err != nil || !equal(trace, decoded) {
+		t.Errorf("Text round trip failed: %v", err)
+	}
+
+	jsonData, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	if err = json.Unmarshal(jsonData, &decoded); err != nil || !equal(trace, decoded) {
+		t.Errorf("JSON round trip failed: %v", err)
+	}
+
+	if err = json.Unmarshal([]byte(`[["1","2"],["3"]]`), &decoded); err == nil {
+		t.Errorf("UnmarshalJSON should reject rows of different width")
+	}
+
+	header := func(rows, width uint32) []byte {
+		return binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, rows), width)
+	}
+
+	// 2^31 rows of 2^30 elements are 2^64 bytes, which wraps around to the 0 bytes present
+	if err = decoded.UnmarshalBinary(header(1<<31, 1<<30)); err == nil {
+		t.Errorf("UnmarshalBinary should reject a size that overflows to zero")
+	}
+
+	if err = decoded.UnmarshalBinary(header(^uint32(0), 0)); err == nil {
+		t.Errorf("UnmarshalBinary should reject rows of width 0")
+	}
+
+	if err = decoded.UnmarshalBinary(header(0, 0)); err != nil || len(decoded) != 0 {
+		t.Errorf("UnmarshalBinary should accept the empty trace: %v", err)
+	}
+}
+
+func FuzzExecutionTraceUnmarshalBinary(f *testing.F) {
+	data, _ := air.Compute([]*math.PrimeField{math.NewPrimeField(5), math.NewPrimeField(12)}).Trace().MarshalBinary()
+	f.Add(data)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0x80})
+	f.Add([]byte{0, 0, 0, 0x80, 0, 0, 0, 0x40})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var trace air.ExecutionTrace
+		if err := trace.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// An empty trace forgets its width in the header
+		encoded, err := trace.MarshalBinary()
+		if err != nil || !bytes.Equal(encoded[8:], data[8:]) {
+			t.Errorf("Decoded trace does not encode back to its input: %v", err)
+		}
+	})
+}
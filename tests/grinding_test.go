@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestGrinding(t *testing.T) {
+	params := stark.DefaultParameters()
+	params.GrindingBits = 12
+
+	receipt := air.ComputePadded(receiptPrices(10))
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	data, _ := proof.MarshalBinary()
+	var decoded stark.Proof
+	if err = decoded.UnmarshalBinary(data); err != nil || decoded.PowNonce != proof.PowNonce {
+		t.Errorf("Nonce did not survive encoding: %v", err)
+	}
+
+	proof.PowNonce++
+	if err = stark.Verify(receipt.AIR(), proof, params); err == nil {
+		t.Errorf("Verify accepted a wrong nonce")
+	}
+}
+
+func TestGrindingCancellation(t *testing.T) {
+	params := stark.DefaultParameters()
+	params.GrindingBits = 32
+
+	receipt := air.ComputePadded(receiptPrices(3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := stark.ProveContext(ctx, receipt.AIR(), receipt.Trace(), params)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Grinding did not stop after cancellation")
+	}
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/ingest"
+	"github.com/KyrylR/simple-air/math"
+)
+
+func TestParseAmount(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		scale int
+		value uint64
+		err   error
+	}{
+		{"12.34", 2, 1234, nil},
+		{"12", 2, 1200, nil},
+		{"12.5", 2, 1250, nil},
+		{".5", 2, 50, nil},
+		{"7.", 2, 700, nil},
+		{"1.230", 2, 123, nil},
+		{"0.001", 3, 1, nil},
+		{"42", 0, 42, nil},
+		{"18446744069414584320", 0, 18446744069414584320, nil},
+		{"1.234", 2, 0, ingest.ErrPrecision},
+		{"18446744069414584321", 0, 0, ingest.ErrOverflow},
+		{"184467440694145843.21", 2, 0, ingest.ErrOverflow},
+		{"99999999999999999999", 0, 0, ingest.ErrOverflow},
+		{"-1.00", 2, 0, ingest.ErrSyntax},
+		{"1,000.00", 2, 0, ingest.ErrSyntax},
+		{"1e3", 2, 0, ingest.ErrSyntax},
+		{".", 2, 0, ingest.ErrSyntax},
+		{"", 2, 0, ingest.ErrSyntax},
+	} {
+		value, err := ingest.ParseAmount(tc.text, tc.scale)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("ParseAmount(%q, %d) returned %v, expected %v", tc.text, tc.scale, err, tc.err)
+			}
+			continue
+		}
+
+		if err != nil || value != tc.value {
+			t.Errorf("ParseAmount(%q, %d) = %d, %v, expected %d", tc.text, tc.scale, value, err, tc.value)
+		}
+	}
+
+	if _, err := ingest.ParseAmount("1", ingest.MaxScale+1); err == nil {
+		t.Errorf("ParseAmount accepted a scale above MaxScale")
+	}
+
+	if formatted := ingest.FormatAmount(5, 2); formatted != "0.05" {
+		t.Errorf("FormatAmount(5, 2) = %s, expected 0.05", formatted)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "SKU, Price ,qty,name\n" +
+		"A-1,12.34,2,coffee\n" +
+		"\n" +
+		"B-2,0.99,1,\"milk, 1l\"\n" +
+		"C-3,100,3\n"
+
+	receipt, err := ingest.ReadCSV(strings.NewReader(input), ingest.DefaultScale)
+	if err != nil {
+		t.Fatalf("ReadCSV failed: %v", err)
+	}
+
+	expected := []ingest.Line{
+		{SKU: "A-1", Quantity: 2, UnitPrice: 1234},
+		{SKU: "B-2", Quantity: 1, UnitPrice: 99},
+		{SKU: "C-3", Quantity: 3, UnitPrice: 10000},
+	}
+	if len(receipt.Lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(receipt.Lines))
+	}
+
+	for i, line := range expected {
+		if receipt.Lines[i] != line {
+			t.Errorf("Line %d is %+v, expected %+v", i, receipt.Lines[i], line)
+		}
+	}
+
+	if total := ingest.FormatAmount(receipt.Total(), receipt.Scale); total != "325.67" {
+		t.Errorf("Total is %s, expected 325.67", total)
+	}
+
+	r := receipt.Compute()
+	if err = air.Check(r.AIR(), r.Trace()); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+
+	if !r.AIR().Total.Equals(math.NewPrimeFieldUint64(32567)) {
+		t.Errorf("Receipt total is %v, expected 32567", r.AIR().Total)
+	}
+}
+
+func TestReadCSVLineErrors(t *testing.T) {
+	input := "sku,qty,price\n" +
+		"A-1,2,12.34\n" +
+		",1,1.00\n" +
+		"B-2,zero,1.999\n" +
+		"C-3,1\n" +
+		"D-4,20,18446744069414584.31\n" +
+		"E-5,1,184467440694145843.20\n"
+
+	_, err := ingest.ReadCSV(strings.NewReader(input), ingest.DefaultScale)
+
+	var errs ingest.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ReadCSV returned %v, expected line errors", err)
+	}
+
+	expected := []struct {
+		line  int
+		field string
+		err   error
+	}{
+		{3, "sku", ingest.ErrMissing},
+		{4, "qty", ingest.ErrSyntax},
+		{4, "price", ingest.ErrPrecision},
+		{5, "price", ingest.ErrMissing},
+		{6, "", ingest.ErrOverflow},
+		{7, "", ingest.ErrOverflow},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), err)
+	}
+
+	for i, e := range expected {
+		if errs[i].Line != e.line || errs[i].Field != e.field || !errors.Is(errs[i], e.err) {
+			t.Errorf("Error %d is %v, expected line %d %s: %v", i, errs[i], e.line, e.field, e.err)
+		}
+	}
+
+	if !errors.Is(err, ingest.ErrPrecision) {
+		t.Errorf("errors.Is does not find the line errors")
+	}
+
+	if _, err = ingest.ReadCSV(strings.NewReader("sku,price\nA,1\n"), 2); err == nil {
+		t.Errorf("ReadCSV accepted a header without qty")
+	}
+
+	var lineErr *ingest.LineError
+	if _, err = ingest.ReadCSV(strings.NewReader("sku,qty,price\nA,1,\"1\n"), 2); !errors.As(err, &lineErr) {
+		t.Errorf("ReadCSV returned %v for malformed CSV, expected a line error", err)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `{"lines": [
+		{"sku": "A-1", "qty": 2, "price": "12.34"},
+		{"sku": "B-2", "qty": "1", "price": 0.99, "name": "milk"}
+	]}`
+
+	receipt, err := ingest.ReadJSON(strings.NewReader(input), ingest.DefaultScale)
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+
+	if receipt.Total() != 2567 {
+		t.Errorf("Total is %d, expected 2567", receipt.Total())
+	}
+
+	input = `{"lines": [
+		{"sku": "A-1", "qty": 2, "price": "12.34"},
+		{"sku": "B-2", "qty": 1.5, "price": "1"},
+		{"sku": "C-3", "qty": 1, "price": true},
+		{"qty": 1, "price": null}
+	]}`
+
+	_, err = ingest.ReadJSON(strings.NewReader(input), ingest.DefaultScale)
+
+	var errs ingest.Errors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("ReadJSON returned %v, expected 4 line errors", err)
+	}
+
+	for i, line := range []int{2, 3, 4, 4} {
+		if errs[i].Line != line {
+			t.Errorf("Error %d is on line %d, expected %d", i, errs[i].Line, line)
+		}
+	}
+
+	if _, err = ingest.ReadJSON(strings.NewReader(`{"lines": []}`), 2); err == nil {
+		t.Errorf("ReadJSON accepted a receipt without lines")
+	}
+
+	if _, err = ingest.ReadJSON(strings.NewReader(`[`), 2); err == nil {
+		t.Errorf("ReadJSON accepted malformed JSON")
+	}
+}
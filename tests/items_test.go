@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func lineItems() []air.LineItem {
+	return []air.LineItem{
+		air.NewLineItem(2, 150),
+		air.NewLineItem(1, 999),
+		air.NewLineItem(3, 45),
+		air.NewLineItem(10, 12),
+	}
+}
+
+func TestComputeItems(t *testing.T) {
+	receipt := air.ComputeItemsPadded(lineItems())
+
+	if len(receipt.Sums) != 8 {
+		t.Fatalf("Expected padded trace of 8 rows, got %d", len(receipt.Sums))
+	}
+
+	if !receipt.Total().Equals(math.NewPrimeField(2*150 + 999 + 3*45 + 10*12)) {
+		t.Errorf("Unexpected grand total %v", receipt.Total())
+	}
+
+	if err := air.Check(receipt.AIR(), receipt.Trace()); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+}
+
+func TestItemizedAIRRejectsWrongLineTotal(t *testing.T) {
+	receipt := air.ComputeItemsPadded(lineItems())
+
+	// Consistent running sums over a line total that is not quantity times unit price
+	receipt.LineTotals[1] = new(math.PrimeField).Add(receipt.LineTotals[1], math.NewPrimeField(1))
+	for i := 2; i < len(receipt.Sums); i++ {
+		receipt.Sums[i] = new(math.PrimeField).Add(receipt.Sums[i-1], receipt.LineTotals[i-1])
+	}
+
+	if err := air.Check(receipt.AIR(), receipt.Trace()); err == nil {
+		t.Errorf("Check accepted a wrong line total")
+	}
+}
+
+func TestItemizedProveVerify(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputeItemsPadded(lineItems())
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	wrongTotal := receipt.AIR()
+	wrongTotal.Total = math.NewPrimeField(1)
+	if err = stark.Verify(wrongTotal, proof, params); !errors.Is(err, stark.ErrVerification) {
+		t.Errorf("Verify accepted a wrong total: %v", err)
+	}
+
+	params.ZeroKnowledge = true
+	// The masked trace of 8 rows has a degree bound of 128 and needs a larger blowup
+	params.BlowupFactor = 32
+	if proof, err = stark.Prove(receipt.AIR(), receipt.Trace(), params); err != nil {
+		t.Fatalf("Prove failed in zero-knowledge mode: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed in zero-knowledge mode: %v", err)
+	}
+}
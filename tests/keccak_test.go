@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/KyrylR/simple-air/hash/keccak"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestKeccak256(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{"transfer(address,uint256)", "a9059cbb2ab09eb219583f4a59a5d0623ade346d962bcd4e46b11da047c9049b"},
+		{strings.Repeat("\x00", 200), "e1bb54e1bc3af48d01e5dbfc81015c98152a574f6428c6948aa4837c9c0baad9"},
+	} {
+		digest := keccak.Sum256([]byte(tc.input))
+		if hex.EncodeToString(digest[:]) != tc.expected {
+			t.Errorf("Keccak-256 of %d bytes is %x, expected %s", len(tc.input), digest, tc.expected)
+		}
+	}
+}
+
+func TestKeccakHasher(t *testing.T) {
+	hasher, err := stark.HashKeccak256.Hasher()
+	if err != nil {
+		t.Fatalf("Hasher failed: %v", err)
+	}
+
+	// Elements are 32-byte big-endian words after the leaf tag, as abi.encodePacked encodes bytes1 and uint256 values
+	elements := []*math.PrimeField{math.NewPrimeField(1), math.NewPrimeFieldUint64(math.Modulus - 1)}
+
+	words := make([]byte, 65)
+	words[0] = merkle.LeafTag
+	words[32] = 1
+	copy(words[57:], []byte{0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00})
+
+	if hasher.HashElements(elements) != merkle.Digest(keccak.Sum256(words)) {
+		t.Errorf("HashElements does not hash the elements as EVM words")
+	}
+
+	left, right := hasher.HashElements(elements[:1]), hasher.HashElements(elements[1:])
+	if hasher.Merge(left, right) != merkle.Digest(keccak.Sum256(append(append([]byte{merkle.NodeTag}, left[:]...), right[:]...))) {
+		t.Errorf("Merge does not hash the node tag and the concatenation of the digests")
+	}
+}
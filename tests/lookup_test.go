@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// lookupTrace builds rows (sku, price, table sku, table price, multiplicity)
+func lookupTrace(rows [][5]int64) air.ExecutionTrace {
+	trace := make(air.ExecutionTrace, len(rows))
+	for i, values := range rows {
+		row := make([]*math.PrimeField, len(values))
+		for j, v := range values {
+			row[j] = math.NewPrimeField(v)
+		}
+		trace[i] = math.NewPolynom(row)
+	}
+
+	return trace
+}
+
+func TestLookupCheck(t *testing.T) {
+	check := air.LookupCheck{Values: [][]int{{0, 1}}, Table: []int{2, 3}, Multiplicity: 4, Steps: 4}
+	challenges := randomChallenges(t, air.LookupChallenges)
+
+	evaluate := func(trace air.ExecutionTrace) bool {
+		aux := check.Build([]air.ExecutionTrace{trace}, challenges)
+		return air.CheckSegment(check, []air.ExecutionTrace{trace, aux}, challenges) == nil
+	}
+
+	// SKU 7 costs 100 and SKU 9 costs 250, SKU 7 is bought twice
+	valid := lookupTrace([][5]int64{{7, 100, 7, 100, 2}, {9, 250, 9, 250, 1}, {7, 100, 0, 0, 0}, {0, 0, 0, 0, 0}})
+	if !evaluate(valid) {
+		t.Errorf("Lookup of table rows failed")
+	}
+
+	// The table has no (0, 0) row, the unused last row is not looked up
+	valid[3].Coefficients[0] = math.NewPrimeField(123)
+	if !evaluate(valid) {
+		t.Errorf("Lookup depends on the last row")
+	}
+
+	invented := lookupTrace([][5]int64{{7, 100, 7, 100, 1}, {9, 200, 9, 250, 1}, {0, 0, 0, 0, 1}, {0, 0, 0, 0, 0}})
+	if evaluate(invented) {
+		t.Errorf("Lookup of a price that is not in the table succeeded")
+	}
+
+	miscounted := lookupTrace([][5]int64{{7, 100, 7, 100, 1}, {9, 250, 9, 250, 1}, {7, 100, 0, 0, 0}, {0, 0, 0, 0, 0}})
+	if evaluate(miscounted) {
+		t.Errorf("Lookup with wrong multiplicities succeeded")
+	}
+}
+
+func TestLookupRangeCheckedReceipt(t *testing.T) {
+	receipt := air.ComputeLookupPadded(append(receiptPrices(10), math.NewPrimeFieldUint64(1<<32-1)))
+	statement := receipt.LookupRangeCheckedAIR()
+	trace := receipt.LookupRangeCheckedTrace()
+
+	if statement.TraceLength() != air.LookupRangeSteps {
+		t.Fatalf("Expected %d rows, got %d", air.LookupRangeSteps, statement.TraceLength())
+	}
+
+	challenges := segmentChallenges(t, statement)
+	if err := air.Check(statement, trace); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if _, err := air.BuildSegments(statement, trace, challenges); err != nil {
+		t.Fatalf("BuildSegments failed: %v", err)
+	}
+
+	params := stark.DefaultParameters()
+	params.NumQueries = 16
+
+	proof, err := stark.Prove(statement, trace, params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
+
+func TestLookupRangeCheckedReceiptRejectsNegativePrice(t *testing.T) {
+	receipt := air.ComputeLookupPadded([]*math.PrimeField{
+		math.NewPrimeField(10),
+		new(math.PrimeField).Neg(math.NewPrimeField(5)),
+	})
+
+	statement := receipt.LookupRangeCheckedAIR()
+	trace := receipt.LookupRangeCheckedTrace()
+
+	if err := air.Check(statement, trace); err == nil {
+		t.Errorf("Check accepted a negative price")
+	}
+
+	// Limbs of a negative price recompose it, but are not all in the table
+	limbs := []*math.PrimeField{
+		new(math.PrimeField).Neg(math.NewPrimeField(5)),
+		math.NewPrimeField(0), math.NewPrimeField(0), math.NewPrimeField(0),
+	}
+	copy(trace[1].Coefficients[2:6], limbs)
+
+	if err := air.Check(statement, trace); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	challenges := segmentChallenges(t, statement)
+	if _, err := air.BuildSegments(statement, trace, challenges); err == nil {
+		t.Errorf("BuildSegments accepted a limb outside of the table")
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/hash/keccak"
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestMerkleTree(t *testing.T) {
+	hasher := merkle.SHA256{}
+
+	leaves := make([]merkle.Digest, 8)
+	for i := range leaves {
+		leaves[i] = hasher.HashElements([]*math.PrimeField{math.NewPrimeField(int64(i))})
+	}
+
+	tree, err := merkle.NewTree(hasher, leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+
+	for i, leaf := range leaves {
+		path := tree.Open(i)
+
+		if len(path) != 3 {
+			t.Errorf("Expected path of length 3, got %d", len(path))
+		}
+
+		if !merkle.Verify(hasher, tree.Root(), 3, i, leaf, path) {
+			t.Errorf("Verify failed for leaf %d", i)
+		}
+
+		if merkle.Verify(hasher, tree.Root(), 3, i^1, leaf, path) {
+			t.Errorf("Verify accepted leaf %d at wrong index", i)
+		}
+	}
+
+	if merkle.Verify(hasher, tree.Root(), 3, 0, leaves[1], tree.Open(0)) {
+		t.Errorf("Verify accepted wrong leaf")
+	}
+
+	// An inner node presented as a leaf of a shallower tree
+	node := hasher.Merge(leaves[0], leaves[1])
+	if !merkle.Verify(hasher, tree.Root(), 2, 0, node, tree.Open(0)[1:]) {
+		t.Fatalf("Verify failed for an inner node at its own depth")
+	}
+
+	if merkle.Verify(hasher, tree.Root(), 3, 0, node, tree.Open(0)[1:]) {
+		t.Errorf("Verify accepted a path shorter than the depth")
+	}
+
+	if _, err = merkle.NewTree(hasher, leaves[:3]); err == nil {
+		t.Errorf("NewTree should reject non power of two leaves")
+	}
+}
+
+func TestMerkleDomainSeparation(t *testing.T) {
+	poseidon, err := stark.HashPoseidon2.Hasher()
+	if err != nil {
+		t.Fatalf("Hasher failed: %v", err)
+	}
+
+	for name, hasher := range map[string]merkle.Hasher{
+		"sha256":    merkle.SHA256{},
+		"rescue":    rescue.Hasher{},
+		"poseidon2": poseidon,
+		"keccak256": keccak.Hasher{},
+	} {
+		left := hasher.HashElements([]*math.PrimeField{math.NewPrimeField(1)})
+		right := hasher.HashElements([]*math.PrimeField{math.NewPrimeField(2)})
+
+		// A row of 8 elements has the same bytes, or the same elements, as a pair of digests
+		row := append(merkle.DigestToElements(left), merkle.DigestToElements(right)...)
+		if hasher.Merge(left, right) == hasher.HashElements(row) {
+			t.Errorf("%s: a row hashes like a pair of digests", name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestPeriodicColumn(t *testing.T) {
+	const n = 32
+
+	column := air.PeriodicColumn{math.NewPrimeField(3), math.NewPrimeField(1), math.NewPrimeField(4), math.NewPrimeField(1)}
+	q := column.Polynomial()
+
+	if q.Len() != column.Period() {
+		t.Fatalf("Expected %d coefficients, got %d", column.Period(), q.Len())
+	}
+
+	omega := new(math.PrimeField).GetRootOfUnity(n)
+	for step := range n {
+		x := new(math.PrimeField).Exp(omega, big.NewInt(int64(step)))
+		if !column.EvaluateAt(q, x, n).Equals(column.At(step)) {
+			t.Fatalf("Periodic column at step %d does not match its value", step)
+		}
+	}
+
+	selector := air.Selector(8, 0, 5)
+	for step := range 16 {
+		expected := int64(0)
+		if step%8 == 0 || step%8 == 5 {
+			expected = 1
+		}
+
+		if !selector.At(step).Equals(math.NewPrimeField(expected)) {
+			t.Errorf("Selector at step %d is %v, expected %d", step, selector.At(step), expected)
+		}
+	}
+
+	if err := air.CheckPeriodic([]air.PeriodicColumn{column, selector}, n); err != nil {
+		t.Errorf("CheckPeriodic failed: %v", err)
+	}
+
+	if err := air.CheckPeriodic([]air.PeriodicColumn{make(air.PeriodicColumn, 3)}, n); err == nil {
+		t.Errorf("CheckPeriodic accepted a period that is not a power of two")
+	}
+
+	if err := air.CheckPeriodic([]air.PeriodicColumn{make(air.PeriodicColumn, 2*n)}, n); err == nil {
+		t.Errorf("CheckPeriodic accepted a period longer than the trace")
+	}
+}
+
+func rescueInput(length int) []*math.PrimeField {
+	input := make([]*math.PrimeField, length)
+	for i := range input {
+		input[i] = math.NewPrimeField(int64(i*i + 7))
+	}
+
+	return input
+}
+
+func TestRescueHashAIR(t *testing.T) {
+	for _, length := range []int{0, 3, rescue.Rate, 20, 33} {
+		input := rescueInput(length)
+		execution := air.ComputeRescueHash(input)
+
+		if !math.NewPolynom(execution.Digest()).Equals(math.NewPolynom(rescue.Hash(input))) {
+			t.Fatalf("Digest of %d elements does not match rescue.Hash", length)
+		}
+
+		statement := execution.AIR()
+		if err := air.Check(statement, execution.Trace()); err != nil {
+			t.Fatalf("Check of %d elements failed: %v", length, err)
+		}
+	}
+
+	execution := air.ComputeRescueHash(rescueInput(20))
+	statement := execution.AIR()
+
+	// A round state that is off by one
+	trace := execution.Trace()
+	trace[3].Coefficients[5] = new(math.PrimeField).Add(trace[3].At(5), math.NewPrimeField(1))
+	if err := air.Check(statement, trace); err == nil {
+		t.Errorf("Check accepted a tampered round")
+	}
+
+	// A capacity element changed between permutations
+	trace = execution.Trace()
+	capacity := rescue.Rate + 1
+	for i := 8; i < len(trace); i++ {
+		trace[i].Coefficients[capacity] = new(math.PrimeField).Add(trace[i].At(capacity), math.NewPrimeField(1))
+	}
+	if err := air.Check(statement, trace); err == nil {
+		t.Errorf("Check accepted a capacity that is not carried over")
+	}
+
+	// Padding of the last block with a non-zero element is a different input
+	padded := air.ComputeRescueHash(append(rescueInput(20), math.NewPrimeField(1)))
+	if err := air.Check(statement, padded.Trace()); err == nil {
+		t.Errorf("Check accepted a non-zero padding")
+	}
+}
+
+func TestRescueHashProveVerify(t *testing.T) {
+	execution := air.ComputeRescueHash(rescueInput(20))
+	statement := execution.AIR()
+	params := stark.DefaultParameters()
+
+	proof, err := stark.Prove(statement, execution.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	wrong := air.ComputeRescueHash(rescueInput(19)).AIR()
+	wrong.Length = statement.Length
+	if err = stark.Verify(wrong, proof, params); err == nil {
+		t.Errorf("Verify accepted a wrong digest")
+	}
+}
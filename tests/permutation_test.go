@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func randomChallenges(t *testing.T, n int) []*math.PrimeField {
+	challenges := make([]*math.PrimeField, n)
+	for i := range challenges {
+		challenges[i] = new(math.PrimeField)
+		if _, err := challenges[i].SetRandom(); err != nil {
+			t.Fatalf("SetRandom failed: %v", err)
+		}
+	}
+
+	return challenges
+}
+
+// segmentChallenges draws the challenges of every auxiliary segment of the AIR
+func segmentChallenges(t *testing.T, a air.AuxiliaryAIR) [][]*math.PrimeField {
+	var challenges [][]*math.PrimeField
+	for _, segment := range a.AuxiliarySegments() {
+		challenges = append(challenges, randomChallenges(t, segment.NumChallenges()))
+	}
+
+	return challenges
+}
+
+func TestPermutationCheck(t *testing.T) {
+	check := air.PermutationCheck{Left: []int{0, 1}, Right: []int{2, 3}, Steps: 4}
+	challenges := randomChallenges(t, air.PermutationChallenges)
+
+	rows := [][]int64{{1, 10, 3, 30}, {2, 20, 1, 10}, {3, 30, 2, 20}, {0, 0, 0, 0}}
+
+	trace := make(air.ExecutionTrace, len(rows))
+	for i, values := range rows {
+		row := make([]*math.PrimeField, len(values))
+		for j, v := range values {
+			row[j] = math.NewPrimeField(v)
+		}
+		trace[i] = math.NewPolynom(row)
+	}
+
+	column := check.Build([]air.ExecutionTrace{trace}, challenges)
+	if err := air.CheckSegment(check, []air.ExecutionTrace{trace, column}, challenges); err != nil {
+		t.Errorf("Permutation check failed: %v", err)
+	}
+
+	// Swapping the second elements of two tuples keeps both columns permutations but not the tuples
+	trace[0].Coefficients[3], trace[1].Coefficients[3] = trace[1].Coefficients[3], trace[0].Coefficients[3]
+	column = check.Build([]air.ExecutionTrace{trace}, challenges)
+	if column[len(column)-1].At(0).Equals(math.NewPrimeField(1)) {
+		t.Errorf("Running product of different tuples ends with 1")
+	}
+}
+
+func TestSortedItems(t *testing.T) {
+	items := lineItems()
+	sorted := air.ComputeSortedItems(items)
+
+	for i := 1; i < len(sorted.Sorted); i++ {
+		if sorted.Sorted[i-1].UnitPrice.Cmp(sorted.Sorted[i].UnitPrice) > 0 {
+			t.Fatalf("Items are not sorted at %d", i)
+		}
+	}
+
+	statement := sorted.AIR()
+	trace := sorted.Trace()
+	challenges := segmentChallenges(t, statement)
+
+	if err := air.Check(statement, trace); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if _, err := air.BuildSegments(statement, trace, challenges); err != nil {
+		t.Fatalf("BuildSegments failed: %v", err)
+	}
+
+	// A sorted list that is not a permutation of the scanned items
+	cheat := air.ComputeSortedItems(items)
+	cheat.Sorted[len(cheat.Sorted)-1] = air.NewLineItem(1, 10000)
+	trace = cheat.Trace()
+
+	if err := air.Check(statement, trace); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if _, err := air.BuildSegments(statement, trace, challenges); err == nil {
+		t.Errorf("BuildSegments accepted items that are not a permutation")
+	}
+
+	if _, err := stark.Prove(statement, trace, stark.DefaultParameters()); err == nil {
+		t.Errorf("Prove accepted items that are not a permutation")
+	}
+
+	// A permutation that is not sorted
+	unsorted := air.ComputeSortedItems(items)
+	unsorted.Sorted = unsorted.Scanned
+	if err := air.Check(statement, unsorted.Trace()); err == nil {
+		t.Errorf("Check accepted unsorted items")
+	}
+}
+
+func TestSortedItemsProveVerify(t *testing.T) {
+	sorted := air.ComputeSortedItems(lineItems())
+	statement := sorted.AIR()
+
+	for _, zk := range []bool{false, true} {
+		params := stark.DefaultParameters()
+		if zk {
+			params.BlowupFactor = 32
+			params.ZeroKnowledge = true
+		}
+
+		proof, err := stark.Prove(statement, sorted.Trace(), params)
+		if err != nil {
+			t.Fatalf("Prove failed: %v", err)
+		}
+
+		if len(proof.AuxiliaryRoots) != 1 || len(proof.Queries[0].Auxiliary) != 1 {
+			t.Fatalf("Expected one auxiliary segment in the proof")
+		}
+
+		data, _ := proof.MarshalBinary()
+		var decoded stark.Proof
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+
+		if err = stark.Verify(statement, &decoded, params); err != nil {
+			t.Errorf("Verify failed with zero-knowledge %v: %v", zk, err)
+		}
+
+		decoded.AuxiliaryRoots[0][0] ^= 1
+		if err = stark.Verify(statement, &decoded, params); err == nil {
+			t.Errorf("Verify accepted a tampered auxiliary commitment")
+		}
+
+		proof.AuxiliaryRoots = nil
+		if err = stark.Verify(statement, proof, params); err == nil {
+			t.Errorf("Verify accepted a proof without the auxiliary commitment")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/math"
+)
+
+func TestPolynomString(t *testing.T) {
+	poly := math.NewPolynom([]*math.PrimeField{
+		math.NewPrimeField(5),
+		math.NewPrimeField(3),
+		math.NewPrimeField(2),
+	})
+
+	if poly.String() != "5 + 3x + 2x^2" {
+		t.Errorf("String failed. Expected 5 + 3x + 2x^2, got %v", poly.String())
+	}
+
+	sparse := math.NewPolynomSparse(map[int]*math.PrimeField{
+		1:  math.NewPrimeField(1),
+		12: math.NewPrimeField(32),
+	})
+
+	if sparse.String() != "x + 32x^12" {
+		t.Errorf("String failed. Expected x + 32x^12, got %v", sparse.String())
+	}
+
+	zero := math.NewPolynom([]*math.PrimeField{math.NewPrimeField(0), nil})
+
+	if zero.String() != "0" {
+		t.Errorf("String failed. Expected 0, got %v", zero.String())
+	}
+}
+
+func TestPolynomFormatSigned(t *testing.T) {
+	poly := math.NewPolynom([]*math.PrimeField{
+		math.NewPrimeField(-3),
+		math.NewPrimeField(0),
+		math.NewPrimeField(-1),
+		math.NewPrimeField(7),
+	})
+
+	signed := math.PolynomFormat{Signed: true, Variable: "t"}.Format(poly)
+	if signed != "-3 - t^2 + 7t^3" {
+		t.Errorf("Format failed. Expected -3 - t^2 + 7t^3, got %v", signed)
+	}
+
+	unsigned := math.PolynomFormat{}.Format(poly)
+	if unsigned != "18446744069414584318 + 18446744069414584320x^2 + 7x^3" {
+		t.Errorf("Format failed. Got %v", unsigned)
+	}
+}
+
+func TestParsePolynom(t *testing.T) {
+	res, err := math.ParsePolynom("5 + 3x + 2*x^2 - x^4")
+	if err != nil {
+		t.Fatalf("ParsePolynom failed: %v", err)
+	}
+
+	expected := math.NewPolynom([]*math.PrimeField{
+		math.NewPrimeField(5),
+		math.NewPrimeField(3),
+		math.NewPrimeField(2),
+		math.NewPrimeField(0),
+		math.NewPrimeField(-1),
+	})
+
+	if !res.Equals(expected) {
+		t.Errorf("ParsePolynom failed. Expected %v, got %v", expected, res)
+	}
+
+	res, err = math.ParsePolynom("-x + 2x - 1 + 1")
+	if err != nil {
+		t.Fatalf("ParsePolynom failed: %v", err)
+	}
+
+	if !res.Equals(math.NewPolynom([]*math.PrimeField{math.NewPrimeField(0), math.NewPrimeField(1)})) {
+		t.Errorf("ParsePolynom did not combine terms, got %v", res)
+	}
+
+	res, err = math.ParsePolynom("0")
+	if err != nil || res.Len() != 1 || !res.IsZero() {
+		t.Errorf("ParsePolynom failed to parse zero polynom, got %v, %v", res, err)
+	}
+}
+
+func TestParsePolynomRoundTrip(t *testing.T) {
+	poly := math.NewPolynom([]*math.PrimeField{
+		math.NewPrimeField(-17),
+		math.NewPrimeField(80),
+		math.NewPrimeField(0),
+		math.NewPrimeField(1),
+	})
+
+	for _, format := range []math.PolynomFormat{{}, {Signed: true}, {Variable: "y"}} {
+		res, err := format.Parse(format.Format(poly))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		if !res.Equals(poly) {
+			t.Errorf("Round trip failed. Expected %v, got %v", poly, res)
+		}
+	}
+}
+
+func TestParsePolynomErrors(t *testing.T) {
+	inputs := []string{
+		"",
+		"5 +",
+		"3y",
+		"x^",
+		"x^-1",
+		"2x3",
+		"18446744069414584321",
+		"x^4000000000",
+		"0x^1048577",
+	}
+
+	for _, input := range inputs {
+		if _, err := math.ParsePolynom(input); err == nil {
+			t.Errorf("ParsePolynom(%q) should fail", input)
+		}
+	}
+
+	if p, err := math.ParsePolynom("x^1048576"); err != nil || p.Len() != math.MaxParseDegree+1 {
+		t.Errorf("ParsePolynom should accept the degree MaxParseDegree: %v", err)
+	}
+
+	var p math.Polynom
+	if err := p.UnmarshalText([]byte("1 + x^4000000000")); err == nil {
+		t.Errorf("UnmarshalText should reject exponents above MaxParseDegree")
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/hash/poseidon2"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// TestPoseidon2KnownAnswers checks the permutation of [0, 1, ..., width-1] against the test vectors
+// of the Goldilocks width 8 and width 12 instances of the reference implementation
+func TestPoseidon2KnownAnswers(t *testing.T) {
+	expected := map[int][]uint64{
+		8: {
+			0xc5fb1cfe0b4697bb, 0x4a4a32ff849af473, 0xd2fd266077f8efba, 0xf4ad9b74e833916d,
+			0xe6648eb0acc11463, 0x8d5529a930d75194, 0xe8c993aa10da6c90, 0xa73104a95b68031c,
+		},
+		12: {
+			0x01eaef96bdf1c0c1, 0x1f0d2cc525b2540c, 0x6282c1dfe1e0358d, 0xe780d721f698e1e6,
+			0x280c0b6f753d833b, 0x1b942dd5023156ab, 0x43f0df3fcccb8398, 0xe8e8190585489025,
+			0x56bdbf72f77ada22, 0x7911c32bf9dcd705, 0xec467926508fbe67, 0x6a50450ddf85a6ed,
+		},
+	}
+
+	for width, vector := range expected {
+		permutation, err := poseidon2.New(width)
+		if err != nil {
+			t.Fatalf("New(%d) failed: %v", width, err)
+		}
+
+		input := make([]*math.PrimeField, width)
+		for i := range input {
+			input[i] = math.NewPrimeField(int64(i))
+		}
+
+		res := permutation.Hash(input)
+
+		for i := range vector {
+			if res[i].Uint64() != vector[i] {
+				t.Errorf("Width %d: expected 0x%016x at %d, got 0x%016x", width, vector[i], i, res[i].Uint64())
+			}
+
+			if input[i].Uint64() != uint64(i) {
+				t.Errorf("Width %d: Hash modified the input", width)
+			}
+		}
+	}
+
+	if _, err := poseidon2.New(16); err == nil {
+		t.Errorf("New accepted the unsupported width 16")
+	}
+}
+
+func TestPoseidon2Sponge(t *testing.T) {
+	permutation, _ := poseidon2.New(12)
+
+	elements := make([]*math.PrimeField, 11)
+	for i := range elements {
+		elements[i] = math.NewPrimeField(int64(i * i))
+	}
+
+	whole := poseidon2.NewSponge(permutation)
+	whole.Absorb(elements)
+	expected := whole.Squeeze(6)
+
+	split := poseidon2.NewSponge(permutation)
+	split.Absorb(elements[:3])
+	split.Absorb(elements[3:])
+	first := split.Squeeze(2)
+	second := split.Squeeze(4)
+
+	res := append(first, second...)
+	for i := range expected {
+		if !res[i].Equals(expected[i]) {
+			t.Errorf("Squeeze is not consistent across calls at %d", i)
+		}
+	}
+
+	padded := poseidon2.NewSponge(permutation)
+	padded.Absorb(append(elements, math.NewPrimeField(0)))
+	if padded.Squeeze(1)[0].Equals(expected[0]) {
+		t.Errorf("Absorbing a trailing zero does not change the output")
+	}
+}
+
+func TestPoseidon2Hasher(t *testing.T) {
+	hasher, err := stark.HashPoseidon2.Hasher()
+	if err != nil {
+		t.Fatalf("Hasher failed: %v", err)
+	}
+
+	leaves := make([]merkle.Digest, 4)
+	for i := range leaves {
+		leaves[i] = hasher.HashElements([]*math.PrimeField{math.NewPrimeField(int64(i)), math.NewPrimeField(1)})
+	}
+
+	if leaves[0] == leaves[1] {
+		t.Errorf("Different rows hash to the same digest")
+	}
+
+	for _, element := range poseidon2.DigestToElements(leaves[0]) {
+		if element.Uint64() >= math.Modulus {
+			t.Errorf("Digest is not canonical")
+		}
+	}
+
+	tree, err := merkle.NewTree(hasher, leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+
+	if !merkle.Verify(hasher, tree.Root(), tree.Depth(), 2, leaves[2], tree.Open(2)) {
+		t.Errorf("Verify failed with Poseidon2 hasher")
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/merkle"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func sampleProof() *stark.Proof {
+	elements := func(values ...int64) []*math.PrimeField {
+		out := make([]*math.PrimeField, len(values))
+		for i, v := range values {
+			out[i] = math.NewPrimeField(v)
+		}
+		return out
+	}
+
+	digest := func(b byte) merkle.Digest {
+		var d merkle.Digest
+		d[0] = b
+		return d
+	}
+
+	return &stark.Proof{
+		Parameters:      stark.DefaultParameters(),
+		TraceLength:     4,
+		TraceWidth:      2,
+		TraceRoot:       digest(1),
+		CompositionRoot: digest(2),
+		OOD: stark.OODFrame{
+			Current:     elements(1, 2),
+			Next:        elements(3, 4),
+			Composition: elements(-5),
+		},
+		FRI: stark.FRIProof{
+			Roots:     []merkle.Digest{digest(3), digest(4)},
+			Remainder: elements(7, 8, 9),
+		},
+		Queries: []stark.Query{
+			{
+				Index:       5,
+				Trace:       stark.Opening{Values: elements(10, 11), Path: merkle.Path{digest(5), digest(6)}},
+				Composition: stark.Opening{Values: elements(12), Path: merkle.Path{digest(7)}},
+				FRI: []stark.Opening{
+					{Values: elements(13, 14), Path: merkle.Path{digest(8)}},
+				},
+			},
+		},
+	}
+}
+
+func TestProofMarshalBinary(t *testing.T) {
+	proof := sampleProof()
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("SAIR")) {
+		t.Errorf("Encoded proof does not start with magic")
+	}
+
+	var decoded stark.Proof
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	encoded, _ := decoded.MarshalBinary()
+	if !bytes.Equal(data, encoded) {
+		t.Errorf("Round trip changed the encoding")
+	}
+
+	if decoded.Queries[0].Index != 5 || !decoded.OOD.Composition[0].Equals(math.NewPrimeField(-5)) {
+		t.Errorf("Round trip changed the proof")
+	}
+
+	total := 0
+	for _, size := range proof.Sizes() {
+		total += size.Bytes
+	}
+
+	if total != len(data) {
+		t.Errorf("Sizes sum up to %d, encoded proof has %d bytes", total, len(data))
+	}
+}
+
+func TestProofUnmarshalBinaryRejects(t *testing.T) {
+	data, _ := sampleProof().MarshalBinary()
+
+	var decoded stark.Proof
+
+	if err := decoded.UnmarshalBinary(append(append([]byte{}, data...), 0)); !errors.Is(err, math.ErrTrailingBytes) {
+		t.Errorf("UnmarshalBinary should reject trailing bytes, got %v", err)
+	}
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, stark.ErrInvalidProof) {
+		t.Errorf("UnmarshalBinary should reject truncated proof, got %v", err)
+	}
+
+	version := append([]byte{}, data...)
+	version[4] = 99
+	if err := decoded.UnmarshalBinary(version); !errors.Is(err, stark.ErrInvalidProof) {
+		t.Errorf("UnmarshalBinary should reject unknown version, got %v", err)
+	}
+
+	fingerprint := append([]byte{}, data...)
+	fingerprint[6] ^= 1
+	if err := decoded.UnmarshalBinary(fingerprint); !errors.Is(err, stark.ErrInvalidProof) {
+		t.Errorf("UnmarshalBinary should reject fingerprint mismatch, got %v", err)
+	}
+
+	proof := sampleProof()
+	proof.Parameters.NumQueries = 64
+	other, _ := proof.MarshalBinary()
+	if bytes.Equal(data[6:14], other[6:14]) {
+		t.Errorf("Fingerprint does not depend on parameters")
+	}
+}
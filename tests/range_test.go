@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestRangeCheck(t *testing.T) {
+	check := air.RangeCheck{Column: 0, Offset: 1, Bits: air.RangeBits}
+
+	tests := []struct {
+		value uint64
+		valid bool
+	}{
+		{0, true},
+		{1, true},
+		{1<<32 - 1, true},
+		{1 << 32, false},
+		{math.Modulus - 5, false},
+	}
+
+	for _, tt := range tests {
+		value := math.NewPrimeFieldUint64(tt.value)
+		row := append([]*math.PrimeField{value}, check.Decompose(value)...)
+
+		valid := true
+		for _, res := range check.Evaluate(row) {
+			valid = valid && res.IsZero()
+		}
+
+		if valid != tt.valid {
+			t.Errorf("Range check of %d: expected %v, got %v", tt.value, tt.valid, valid)
+		}
+	}
+
+	// Non-binary "bits" that recompose a large value must be rejected
+	row := []*math.PrimeField{math.NewPrimeFieldUint64(math.Modulus - 5), math.NewPrimeFieldUint64(math.Modulus - 5)}
+	for range air.RangeBits - 1 {
+		row = append(row, math.NewPrimeField(0))
+	}
+
+	res := check.Evaluate(row)
+	if res[len(res)-1].IsZero() == res[0].IsZero() {
+		t.Errorf("Expected only the bit constraint to fail for a non-binary decomposition")
+	}
+}
+
+func TestRangeCheckedReceiptRejectsNegativePrice(t *testing.T) {
+	// 10 + (p - 5) = 5 in the field
+	receipt := air.ComputePadded([]*math.PrimeField{
+		math.NewPrimeField(10),
+		new(math.PrimeField).Neg(math.NewPrimeField(5)),
+	})
+
+	if err := air.Check(receipt.AIR(), receipt.Trace()); err != nil {
+		t.Fatalf("Expected the plain receipt to accept a negative price: %v", err)
+	}
+
+	if err := air.Check(receipt.RangeCheckedAIR(), receipt.RangeCheckedTrace()); err == nil {
+		t.Errorf("Check accepted a negative price")
+	}
+
+	if _, err := stark.Prove(receipt.RangeCheckedAIR(), receipt.RangeCheckedTrace(), stark.DefaultParameters()); err == nil {
+		t.Errorf("Prove accepted a negative price")
+	}
+}
+
+func TestRangeCheckedReceiptProveVerify(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputePadded(append(receiptPrices(5), math.NewPrimeFieldUint64(1<<32-1)))
+
+	if err := air.Check(receipt.RangeCheckedAIR(), receipt.RangeCheckedTrace()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	proof, err := stark.Prove(receipt.RangeCheckedAIR(), receipt.RangeCheckedTrace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.RangeCheckedAIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err == nil {
+		t.Errorf("Verify accepted a range-checked proof for the plain statement")
+	}
+}
+
+func TestItemizedAIRRejectsWrappingLines(t *testing.T) {
+	// (p - 1) · 3 = p - 3 in the field: a line total that hides a negative quantity
+	receipt := air.ComputeItemsPadded([]air.LineItem{
+		{Quantity: new(math.PrimeField).Neg(math.NewPrimeField(1)), UnitPrice: math.NewPrimeField(3)},
+		air.NewLineItem(1, 10),
+	})
+
+	if err := air.Check(receipt.AIR(), receipt.Trace()); err == nil {
+		t.Errorf("Check accepted a negative quantity")
+	}
+
+	// 2^20 · 2^20 fits both operands but not the line total
+	receipt = air.ComputeItemsPadded([]air.LineItem{air.NewLineItem(1<<20, 1<<20)})
+	if err := air.Check(receipt.AIR(), receipt.Trace()); err == nil {
+		t.Errorf("Check accepted a line total above 2^32")
+	}
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/hash/rescue"
+	"github.com/KyrylR/simple-air/math"
+)
+
+func TestRescueMDSInverse(t *testing.T) {
+	for i := range rescue.Width {
+		unit := make([]*math.PrimeField, rescue.Width)
+		for j := range unit {
+			unit[j] = new(math.PrimeField).SetZero()
+		}
+		unit[i].SetOne()
+
+		res := rescue.MulMatrix(rescue.MDSInv, rescue.MulMatrix(rescue.MDS, unit))
+		if !math.NewPolynom(res).Equals(math.NewPolynom(unit)) {
+			t.Errorf("MDSInv is not the inverse of MDS at column %d", i)
+		}
+	}
+}
+
+func TestRescueSBoxInverse(t *testing.T) {
+	state := make([]*math.PrimeField, rescue.Width)
+	for i := range state {
+		state[i] = new(math.PrimeField)
+		_, _ = state[i].SetRandom()
+	}
+
+	res := rescue.PowAlphaInv(rescue.PowAlpha(state))
+	if !math.NewPolynom(res).Equals(math.NewPolynom(state)) {
+		t.Errorf("PowAlphaInv is not the inverse of PowAlpha")
+	}
+}
+
+func TestRescueHash(t *testing.T) {
+	input := []*math.PrimeField{math.NewPrimeField(1), math.NewPrimeField(2), math.NewPrimeField(3)}
+
+	digest := rescue.Hash(input)
+	if len(digest) != rescue.DigestElements {
+		t.Errorf("Expected %d digest elements, got %d", rescue.DigestElements, len(digest))
+	}
+
+	if !math.NewPolynom(rescue.Hash(input)).Equals(math.NewPolynom(digest)) {
+		t.Errorf("Hash is not deterministic")
+	}
+
+	padded := append(input, math.NewPrimeField(0))
+	if math.NewPolynom(rescue.Hash(padded)).Equals(math.NewPolynom(digest)) {
+		t.Errorf("Hash does not separate inputs of different length")
+	}
+
+	long := make([]*math.PrimeField, 2*rescue.Rate+1)
+	for i := range long {
+		long[i] = math.NewPrimeField(int64(i))
+	}
+
+	if len(rescue.Hash(long)) != rescue.DigestElements {
+		t.Errorf("Hash of multi-block input has wrong length")
+	}
+}
+
+func TestRescuePreimageAIR(t *testing.T) {
+	preimage := []*math.PrimeField{
+		math.NewPrimeField(42),
+		math.NewPrimeField(1337),
+		math.NewPrimeField(-1),
+	}
+
+	execution, err := air.ComputeRescue(preimage)
+	if err != nil {
+		t.Fatalf("ComputeRescue failed: %v", err)
+	}
+
+	if !math.NewPolynom(execution.Digest()).Equals(math.NewPolynom(rescue.Hash(preimage))) {
+		t.Errorf("Digest does not match rescue.Hash")
+	}
+
+	trace := execution.Trace()
+	if len(trace) != rescue.Rounds+1 || trace.Width() != rescue.Width {
+		t.Fatalf("Unexpected trace shape %dx%d", len(trace), trace.Width())
+	}
+
+	if err = air.CheckTransitions(trace, air.RescueTransitionConstraints); err != nil {
+		t.Errorf("Transition constraints not satisfied: %v", err)
+	}
+
+	assertions := air.RescueAssertions(len(preimage), rescue.Hash(preimage))
+	if err = air.CheckAssertions(trace, assertions); err != nil {
+		t.Errorf("Boundary constraints not satisfied: %v", err)
+	}
+
+	trace[3].Coefficients[5] = new(math.PrimeField).Add(trace[3].At(5), math.NewPrimeField(1))
+	if err = air.CheckTransitions(trace, air.RescueTransitionConstraints); err == nil {
+		t.Errorf("Transition constraints accepted a tampered trace")
+	}
+
+	wrongDigest := air.RescueAssertions(len(preimage), rescue.Hash(preimage[:2]))
+	if err = air.CheckAssertions(execution.Trace(), wrongDigest); err == nil {
+		t.Errorf("Boundary constraints accepted a wrong digest")
+	}
+
+	// The trace shares the states of its execution, tamper with fresh ones
+	fresh, _ := air.ComputeRescue(preimage)
+	extended := append(fresh.Trace(), fresh.Trace()[rescue.Rounds])
+	if err = air.CheckTransitions(extended[:rescue.Rounds+1], air.RescueTransitionConstraints); err != nil {
+		t.Fatalf("Transition constraints not satisfied: %v", err)
+	}
+
+	// Steps after the last round have no round constants
+	if err = air.CheckTransitions(extended, air.RescueTransitionConstraints); err == nil {
+		t.Errorf("Transition constraints accepted a trace longer than the permutation")
+	}
+
+	// A longer preimage padded into the unused rate columns
+	padded := fresh.Trace()
+	padded[0].Coefficients[len(preimage)] = math.NewPrimeField(7)
+	if err = air.CheckAssertions(padded, assertions); err == nil {
+		t.Errorf("Boundary constraints accepted input in the rate after the preimage")
+	}
+
+	if _, err = air.ComputeRescue(make([]*math.PrimeField, rescue.Rate+1)); err == nil {
+		t.Errorf("ComputeRescue should reject preimages longer than the rate")
+	}
+}
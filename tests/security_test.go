@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func TestEstimateSecurity(t *testing.T) {
+	level, err := stark.EstimateSecurity(stark.SecurityInput{
+		FieldBits:           stark.GoldilocksBits,
+		TraceLength:         1024,
+		BlowupFactor:        8,
+		NumQueries:          32,
+		GrindingBits:        0,
+		MaxConstraintDegree: 2,
+	})
+	if err != nil {
+		t.Fatalf("EstimateSecurity failed: %v", err)
+	}
+
+	// Query security is 32·3 = 96 bits, the field bounds it to 64 - log2(8192) - 1 = 50 bits
+	if level.Conjectured != 50 {
+		t.Errorf("Expected 50 conjectured bits, got %d", level.Conjectured)
+	}
+
+	if level.Provable > level.Conjectured {
+		t.Errorf("Provable security %d exceeds conjectured %d", level.Provable, level.Conjectured)
+	}
+
+	extension, err := stark.EstimateSecurity(stark.SecurityInput{
+		FieldBits:           128,
+		TraceLength:         1024,
+		BlowupFactor:        8,
+		NumQueries:          32,
+		GrindingBits:        16,
+		MaxConstraintDegree: 2,
+	})
+	if err != nil {
+		t.Fatalf("EstimateSecurity failed: %v", err)
+	}
+
+	// 32·3 + 16 - 1 = 111 bits from queries and grinding
+	if extension.Conjectured != 111 {
+		t.Errorf("Expected 111 conjectured bits, got %d", extension.Conjectured)
+	}
+
+	// Every query adds -log2(9/16) ≈ 0.83 bits: 32·0.83 + 16 - 1 ≈ 41 bits
+	if extension.Provable != 41 {
+		t.Errorf("Expected 41 provable bits, got %d", extension.Provable)
+	}
+
+	masked, err := stark.EstimateSecurity(stark.SecurityInput{
+		FieldBits:           128,
+		TraceLength:         1024,
+		BlowupFactor:        8,
+		NumQueries:          32,
+		GrindingBits:        16,
+		MaxConstraintDegree: 2,
+		ZeroKnowledge:       true,
+	})
+	if err != nil {
+		t.Fatalf("EstimateSecurity failed: %v", err)
+	}
+
+	// The masks of degree 2·32 + 2 raise the trace degree bound to 2048, the rate to 1/4: 32·2 + 16 - 1 = 79 bits
+	if masked.Conjectured != 79 {
+		t.Errorf("Expected 79 conjectured bits in zero-knowledge mode, got %d", masked.Conjectured)
+	}
+
+	moreQueries := stark.DefaultParameters()
+	moreQueries.NumQueries = 8
+	fewer, _ := moreQueries.Security(1024, 2)
+	moreQueries.NumQueries = 16
+	more, _ := moreQueries.Security(1024, 2)
+
+	if more.Conjectured <= fewer.Conjectured || more.Provable <= fewer.Provable {
+		t.Errorf("More queries did not increase security: %+v vs %+v", fewer, more)
+	}
+
+	if _, err = moreQueries.Security(0, 2); err == nil {
+		t.Errorf("Security accepted a zero trace length")
+	}
+
+	if _, err = stark.EstimateSecurity(stark.SecurityInput{FieldBits: stark.GoldilocksBits, TraceLength: 1024, NumQueries: 32}); err == nil {
+		t.Errorf("EstimateSecurity accepted a zero blowup factor")
+	}
+}
+
+func TestSuggestParameters(t *testing.T) {
+	target := stark.SecurityTarget{
+		Bits:                40,
+		FieldBits:           stark.GoldilocksBits,
+		TraceLength:         1 << 12,
+		TraceWidth:          4,
+		MaxConstraintDegree: 3,
+		MaxBlowup:           32,
+		MaxGrinding:         20,
+	}
+
+	params, level, err := stark.SuggestParameters(target)
+	if err != nil {
+		t.Fatalf("SuggestParameters failed: %v", err)
+	}
+
+	if level.Conjectured < 40 {
+		t.Errorf("Suggested parameters reach only %d bits", level.Conjectured)
+	}
+
+	if err = params.Validate(); err != nil {
+		t.Errorf("Suggested parameters are invalid: %v", err)
+	}
+
+	fewer := params
+	fewer.NumQueries--
+	if level, _ := fewer.Security(target.TraceLength, target.MaxConstraintDegree); fewer.NumQueries > 0 && level.Conjectured >= 40 {
+		t.Errorf("Suggested number of queries %d is not minimal", params.NumQueries)
+	}
+
+	target.Provable = true
+	provable, provableLevel, err := stark.SuggestParameters(target)
+	if err != nil {
+		t.Fatalf("SuggestParameters failed for provable target: %v", err)
+	}
+
+	if provableLevel.Provable < 40 || provable.NumQueries <= params.NumQueries {
+		t.Errorf("Provable target should need more queries: %+v", provable)
+	}
+
+	target.Provable = false
+	target.ZeroKnowledge = true
+	masked, maskedLevel, err := stark.SuggestParameters(target)
+	if err != nil {
+		t.Fatalf("SuggestParameters failed for zero-knowledge target: %v", err)
+	}
+
+	if level, _ := masked.Security(target.TraceLength, target.MaxConstraintDegree); !masked.ZeroKnowledge || maskedLevel.Conjectured < 40 || level != maskedLevel {
+		t.Errorf("Unexpected zero-knowledge parameters %+v with %+v", masked, maskedLevel)
+	}
+
+	target.ZeroKnowledge = false
+	target.Bits = 100
+	if _, _, err = stark.SuggestParameters(target); err == nil {
+		t.Errorf("SuggestParameters should fail to reach 100 bits over Goldilocks")
+	}
+
+	target.FieldBits = 128
+	if _, _, err = stark.SuggestParameters(target); err == nil {
+		t.Errorf("SuggestParameters should reject fields the prover does not use")
+	}
+
+	target.FieldBits = stark.GoldilocksBits
+	target.Bits = 40
+	target.TraceLength = 0
+	if _, _, err = stark.SuggestParameters(target); err == nil {
+		t.Errorf("SuggestParameters should reject a zero trace length")
+	}
+}
+
+func TestEstimateProofSize(t *testing.T) {
+	params := stark.DefaultParameters()
+	params.NumQueries = 4
+
+	masked := params
+	masked.ZeroKnowledge = true
+	masked.BlowupFactor = 32
+
+	receipt := air.ComputePadded(receiptPrices(60))
+
+	batch, err := air.ComputeBatch(batchReceipts())
+	if err != nil {
+		t.Fatalf("ComputeBatch failed: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		air    air.AIR
+		trace  air.ExecutionTrace
+		params stark.Parameters
+	}{
+		{name: "receipt", air: receipt.AIR(), trace: receipt.Trace(), params: params},
+		{name: "zero-knowledge receipt", air: receipt.AIR(), trace: receipt.Trace(), params: masked},
+		{name: "batch with an auxiliary segment", air: batch.AIR(), trace: batch.Trace(), params: params},
+		{name: "zero-knowledge batch", air: batch.AIR(), trace: batch.Trace(), params: masked},
+	}
+
+	for _, c := range cases {
+		proof, err := stark.Prove(c.air, c.trace, c.params)
+		if err != nil {
+			t.Fatalf("%s: Prove failed: %v", c.name, err)
+		}
+
+		data, _ := proof.MarshalBinary()
+
+		estimate, err := stark.EstimateProofSize(c.air, c.params)
+		if err != nil {
+			t.Fatalf("%s: EstimateProofSize failed: %v", c.name, err)
+		}
+
+		if estimate != len(data) {
+			t.Errorf("%s: estimated proof size %d, actual %d", c.name, estimate, len(data))
+		}
+	}
+
+	if _, err = stark.EstimateProofSize(receipt.AIR(), stark.Parameters{}); err == nil {
+		t.Errorf("EstimateProofSize accepted invalid parameters")
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// mixSegment is a segment of one column W = γ·Z + q built from the running product Z of the previous segment
+// and the scanned quantity q of the main trace with its own challenge γ
+type mixSegment struct {
+	// shift breaks the built column to test that it is checked
+	shift int64
+}
+
+func (s mixSegment) Width() int {
+	return 1
+}
+
+func (s mixSegment) NumChallenges() int {
+	return 1
+}
+
+func (s mixSegment) Build(segments []air.ExecutionTrace, challenges []*math.PrimeField) air.ExecutionTrace {
+	main, product := segments[0], segments[1]
+
+	trace := make(air.ExecutionTrace, len(main))
+	for i := range trace {
+		value := new(math.PrimeField).Mul(challenges[0], product[i].At(0))
+		value = new(math.PrimeField).Add(value, main[i].At(air.SortedScannedQuantity))
+		trace[i] = math.NewPolynom([]*math.PrimeField{new(math.PrimeField).Add(value, math.NewPrimeField(s.shift))})
+	}
+
+	return trace
+}
+
+func (s mixSegment) NumConstraints() int {
+	return 1
+}
+
+func (s mixSegment) Evaluate(current, _ [][]*math.PrimeField, challenges []*math.PrimeField) []*math.PrimeField {
+	value := new(math.PrimeField).Mul(challenges[0], current[1][0])
+	value = new(math.PrimeField).Add(value, current[0][air.SortedScannedQuantity])
+
+	return []*math.PrimeField{new(math.PrimeField).Sub(current[2][0], value)}
+}
+
+func (s mixSegment) Assertions(_ []*math.PrimeField) []air.Assertion {
+	return nil
+}
+
+// twoSegmentAIR extends the sorted items statement with a second auxiliary segment
+type twoSegmentAIR struct {
+	*air.SortedItemsAIR
+	mix mixSegment
+}
+
+func (a *twoSegmentAIR) AuxiliarySegments() []air.AuxiliarySegment {
+	return append(a.SortedItemsAIR.AuxiliarySegments(), a.mix)
+}
+
+func TestAuxiliarySegments(t *testing.T) {
+	sorted := air.ComputeSortedItems(lineItems())
+	statement := &twoSegmentAIR{SortedItemsAIR: sorted.AIR()}
+	trace := sorted.Trace()
+
+	segments, err := air.BuildSegments(statement, trace, segmentChallenges(t, statement))
+	if err != nil {
+		t.Fatalf("BuildSegments failed: %v", err)
+	}
+
+	if len(segments) != 3 {
+		t.Fatalf("Expected the main trace and 2 segments, got %d", len(segments))
+	}
+
+	broken := &twoSegmentAIR{SortedItemsAIR: sorted.AIR(), mix: mixSegment{shift: 1}}
+	if _, err = air.BuildSegments(broken, trace, segmentChallenges(t, broken)); err == nil {
+		t.Errorf("BuildSegments accepted a segment that does not satisfy its constraints")
+	}
+
+	if _, err = stark.Prove(broken, trace, stark.DefaultParameters()); err == nil {
+		t.Errorf("Prove accepted a segment that does not satisfy its constraints")
+	}
+}
+
+func TestAuxiliarySegmentsProveVerify(t *testing.T) {
+	sorted := air.ComputeSortedItems(lineItems())
+	statement := &twoSegmentAIR{SortedItemsAIR: sorted.AIR()}
+	params := stark.DefaultParameters()
+
+	proof, err := stark.Prove(statement, sorted.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if len(proof.AuxiliaryRoots) != 2 || len(proof.Queries[0].Auxiliary) != 2 {
+		t.Fatalf("Expected 2 auxiliary commitments and openings, got %d and %d",
+			len(proof.AuxiliaryRoots), len(proof.Queries[0].Auxiliary))
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	decoded := new(stark.Proof)
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, decoded, params); err != nil {
+		t.Errorf("Verify of the decoded proof failed: %v", err)
+	}
+
+	decoded.AuxiliaryRoots[1][0] ^= 1
+	if err = stark.Verify(statement, decoded, params); err == nil {
+		t.Errorf("Verify accepted a tampered second auxiliary commitment")
+	}
+
+	if err = stark.Verify(sorted.AIR(), proof, params); err == nil {
+		t.Errorf("Verify accepted a proof with more auxiliary segments than the AIR")
+	}
+}
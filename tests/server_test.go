@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KyrylR/simple-air/server"
+)
+
+func newTestServer(t *testing.T, config server.Config) *httptest.Server {
+	t.Helper()
+
+	s, err := server.New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		ts.Close()
+		s.Close()
+	})
+
+	return ts
+}
+
+func postReceipt(t *testing.T, ts *httptest.Server, contentType, body string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Post(ts.URL+"/receipts", contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /receipts failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	if err = json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Decode response failed: %v", err)
+	}
+
+	return resp, decoded
+}
+
+// waitJob polls the job until it is finished
+func waitJob(t *testing.T, ts *httptest.Server, id string) server.JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ts.URL + "/jobs/" + id)
+		if err != nil {
+			t.Fatalf("GET /jobs failed: %v", err)
+		}
+
+		var job server.JobResponse
+		err = json.NewDecoder(resp.Body).Decode(&job)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Decode job failed: %v", err)
+		}
+
+		if job.Status == server.StatusDone || job.Status == server.StatusFailed {
+			return job
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Job %s did not finish", id)
+	return server.JobResponse{}
+}
+
+func verifyProof(t *testing.T, ts *httptest.Server, request server.VerifyRequest) server.VerifyResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(request)
+	resp, err := http.Post(ts.URL+"/verify", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /verify failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /verify returned %s", resp.Status)
+	}
+
+	var response server.VerifyResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode verify response failed: %v", err)
+	}
+
+	return response
+}
+
+func TestServerProveVerify(t *testing.T) {
+	ts := newTestServer(t, server.DefaultConfig())
+
+	resp, queued := postReceipt(t, ts, "text/csv", "sku,qty,price\nA-1,2,12.34\nB-2,1,0.99\n")
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /receipts returned %s: %v", resp.Status, queued)
+	}
+
+	if queued["total"] != "2567" || resp.Header.Get("Location") != "/jobs/"+queued["id"].(string) {
+		t.Errorf("Unexpected response %v with location %q", queued, resp.Header.Get("Location"))
+	}
+
+	job := waitJob(t, ts, queued["id"].(string))
+	if job.Status != server.StatusDone || len(job.Proof) == 0 {
+		t.Fatalf("Job finished with %s: %s", job.Status, job.Error)
+	}
+
+	if response := verifyProof(t, ts, server.VerifyRequest{Proof: job.Proof, Total: job.Total}); !response.Valid {
+		t.Errorf("Verify rejected the proof: %s", response.Error)
+	}
+
+	if response := verifyProof(t, ts, server.VerifyRequest{Proof: job.Proof, Total: "2568"}); response.Valid {
+		t.Errorf("Verify accepted a wrong total")
+	}
+
+	resp, queued = postReceipt(t, ts, "application/json", `{"lines": [{"sku": "A-1", "qty": 1, "price": "5"}]}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /receipts of JSON returned %s: %v", resp.Status, queued)
+	}
+
+	if job = waitJob(t, ts, queued["id"].(string)); job.Status != server.StatusDone {
+		t.Errorf("JSON job finished with %s: %s", job.Status, job.Error)
+	}
+
+	metrics, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer metrics.Body.Close()
+
+	text, _ := io.ReadAll(metrics.Body)
+	for _, line := range []string{
+		"simple_air_jobs_submitted_total 2",
+		`simple_air_jobs_finished_total{status="done"} 2`,
+		`simple_air_verifications_total{result="valid"} 1`,
+		`simple_air_verifications_total{result="invalid"} 1`,
+		`simple_air_prove_duration_seconds_count 2`,
+		`simple_air_http_requests_total{method="POST",route="/receipts",code="202"} 2`,
+	} {
+		if !strings.Contains(string(text), line+"\n") {
+			t.Errorf("Metrics do not contain %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestServerRejectsRequests(t *testing.T) {
+	config := server.DefaultConfig()
+	config.MaxBodyBytes = 256
+	config.MaxSteps = 4
+
+	ts := newTestServer(t, config)
+
+	resp, body := postReceipt(t, ts, "text/csv", "sku,qty,price\nA-1,2,12.345\n,1,1\n")
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Invalid receipt returned %s", resp.Status)
+	}
+
+	if lines, _ := body["lines"].([]any); len(lines) != 2 {
+		t.Errorf("Expected 2 line errors, got %v", body)
+	}
+
+	if resp, _ = postReceipt(t, ts, "text/csv", "sku,qty,price\n"+strings.Repeat("A-1,1,1\n", 64)); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Large body returned %s", resp.Status)
+	}
+
+	if resp, _ = postReceipt(t, ts, "text/csv", "sku,qty,price\n"+strings.Repeat("A-1,1,1\n", 4)); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Receipt with too many steps returned %s", resp.Status)
+	}
+
+	if resp, _ = postReceipt(t, ts, "text/plain", "1,2,3"); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Plain text returned %s", resp.Status)
+	}
+
+	get, err := http.Get(ts.URL + "/jobs/unknown")
+	if err != nil {
+		t.Fatalf("GET /jobs failed: %v", err)
+	}
+	get.Body.Close()
+
+	if get.StatusCode != http.StatusNotFound {
+		t.Errorf("Unknown job returned %s", get.Status)
+	}
+
+	post, err := http.Post(ts.URL+"/verify", "application/json", strings.NewReader(`{"proof": "AAAA", "total": "1"}`))
+	if err != nil {
+		t.Fatalf("POST /verify failed: %v", err)
+	}
+	post.Body.Close()
+
+	if post.StatusCode != http.StatusBadRequest {
+		t.Errorf("Malformed proof returned %s", post.Status)
+	}
+}
+
+func TestServerQueueAndTimeout(t *testing.T) {
+	config := server.DefaultConfig()
+	config.Workers = 1
+	config.QueueSize = 1
+	config.JobTimeout = 100 * time.Millisecond
+
+	// Proving this many lines with the default parameters, which do not grind, takes longer than the job timeout
+	var csv strings.Builder
+	csv.WriteString("sku,qty,price\n")
+	for i := range 1 << 12 {
+		fmt.Fprintf(&csv, "A-%d,1,%d\n", i, i+1)
+	}
+
+	ts := newTestServer(t, config)
+
+	var ids []string
+	rejected := false
+	for range 3 {
+		resp, body := postReceipt(t, ts, "text/csv", csv.String())
+		switch resp.StatusCode {
+		case http.StatusAccepted:
+			ids = append(ids, body["id"].(string))
+		case http.StatusServiceUnavailable:
+			rejected = resp.Header.Get("Retry-After") != ""
+		default:
+			t.Fatalf("POST /receipts returned %s", resp.Status)
+		}
+	}
+
+	// One job is proven and one is queued at most
+	if !rejected || len(ids) > 2 {
+		t.Errorf("Queue accepted %d jobs, expected a rejection with Retry-After", len(ids))
+	}
+
+	for _, id := range ids {
+		job := waitJob(t, ts, id)
+		if job.Status != server.StatusFailed || !strings.Contains(job.Error, "deadline") {
+			t.Errorf("Job finished with %s: %q, expected a timeout", job.Status, job.Error)
+		}
+	}
+}
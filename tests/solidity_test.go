@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/hash/keccak"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/solidity"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+var (
+	update = flag.Bool("update", false, "rewrite the golden files of the generated contracts")
+
+	requireEVM = flag.Bool("evm", false, "fail instead of skipping the tests that need solc and evm")
+)
+
+func keccakParameters() stark.Parameters {
+	params := stark.DefaultParameters()
+	params.Hash = stark.HashKeccak256
+
+	return params
+}
+
+func TestSolidityGolden(t *testing.T) {
+	zk := keccakParameters()
+	zk.ZeroKnowledge = true
+	zk.GrindingBits = 4
+
+	for _, tc := range []struct {
+		golden string
+		steps  int
+		params stark.Parameters
+	}{
+		{"receipt_verifier.sol.golden", 8, keccakParameters()},
+		{"receipt_verifier_zk.sol.golden", 32, zk},
+	} {
+		spec, err := solidity.ReceiptSpec(tc.steps, tc.params)
+		if err != nil {
+			t.Fatalf("ReceiptSpec failed: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err = solidity.Generate(&out, spec); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+
+		path := filepath.Join("testdata", tc.golden)
+		if *update {
+			if err = os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+		}
+
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v, run go test -update to create the golden files", err)
+		}
+
+		if !bytes.Equal(out.Bytes(), expected) {
+			t.Errorf("Generated contract differs from %s, run go test -update if the change is intended", path)
+		}
+	}
+}
+
+func TestSolidityEncodeProof(t *testing.T) {
+	params := keccakParameters()
+	params.ZeroKnowledge = true
+
+	receipt := air.ComputePadded(receiptPrices(20))
+	statement := receipt.AIR()
+
+	proof, err := stark.Prove(statement, receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	spec, err := solidity.ReceiptSpec(statement.TraceLength(), params)
+	if err != nil {
+		t.Fatalf("ReceiptSpec failed: %v", err)
+	}
+
+	words, err := solidity.EncodeProof(spec.Shape, proof)
+	if err != nil {
+		t.Fatalf("EncodeProof failed: %v", err)
+	}
+
+	layout := solidity.NewLayout(spec.Shape)
+	if len(words) != layout.Length {
+		t.Fatalf("Proof has %d words, expected %d", len(words), layout.Length)
+	}
+
+	element := func(word solidity.Word) uint64 {
+		return binary.BigEndian.Uint64(word[24:])
+	}
+
+	if words[layout.TraceRoot] != solidity.Word(proof.TraceRoot) || words[layout.CompositionRoot] != solidity.Word(proof.CompositionRoot) {
+		t.Errorf("Roots are not at their positions")
+	}
+
+	if element(words[layout.OODNext]) != proof.OOD.Next[0].Uint64() || element(words[layout.PowNonce]) != proof.PowNonce {
+		t.Errorf("Out-of-domain frame or nonce are not at their positions")
+	}
+
+	if spec.Shape.FRIFolds == 0 || words[layout.FRIRoots] != solidity.Word(proof.FRI.Roots[0]) {
+		t.Errorf("FRI roots are not at their position")
+	}
+
+	// The last query ends with the path of its last FRI layer
+	last := proof.Queries[len(proof.Queries)-1]
+	path := last.FRI[len(last.FRI)-1].Path
+	if words[layout.Length-1] != solidity.Word(path[len(path)-1]) {
+		t.Errorf("Last word is not the end of the last FRI path")
+	}
+
+	query := layout.Queries + (len(proof.Queries)-1)*layout.QuerySize
+	if element(words[query]) != last.Trace.Values[0].Uint64() || element(words[query+2]) != last.Trace.Salt[0].Uint64() {
+		t.Errorf("Trace row of the last query is not at its position")
+	}
+
+	wrong := params
+	wrong.NumQueries++
+	if _, err = solidity.EncodeProof(spec.Shape, &stark.Proof{Parameters: wrong}); err == nil {
+		t.Errorf("EncodeProof accepted a proof with other parameters")
+	}
+}
+
+func TestSolidityCalldata(t *testing.T) {
+	words := []solidity.Word{{31: 1}, {31: 2}}
+	data := solidity.Calldata(words, []*math.PrimeField{math.NewPrimeField(7)})
+
+	selector := keccak.Sum256([]byte(solidity.VerifySignature))
+	if !bytes.Equal(data[:4], selector[:4]) {
+		t.Errorf("Selector is %x, expected %x", data[:4], selector[:4])
+	}
+
+	// Head of two offsets, then the length and the words of both arrays
+	word := func(i int) uint64 {
+		return binary.BigEndian.Uint64(data[4+32*i+24:])
+	}
+
+	expected := []uint64{0x40, 0x40 + 3*32, 2, 1, 2, 1, 7}
+	if len(data) != 4+32*len(expected) {
+		t.Fatalf("Calldata has %d bytes, expected %d", len(data), 4+32*len(expected))
+	}
+
+	for i, value := range expected {
+		if word(i) != value {
+			t.Errorf("Word %d is %d, expected %d", i, word(i), value)
+		}
+	}
+}
+
+// TestSolidityVerifyInEVM compiles the generated contract with solc and calls verify in the evm tool of go-ethereum
+// with a proof of the Go prover, a wrong total and a tampered proof.
+// The test skips without the tools unless -evm is set, which the CI workflow does after installing pinned versions.
+func TestSolidityVerifyInEVM(t *testing.T) {
+	for _, tool := range []string{"solc", "evm"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			if *requireEVM {
+				t.Fatalf("%s is not installed: %v", tool, err)
+			}
+
+			t.Skipf("%s is not installed, the generated contract is only checked against the golden files", tool)
+		}
+	}
+
+	params := keccakParameters()
+	params.NumQueries = 8
+
+	receipt := air.ComputePadded(receiptPrices(7))
+	statement := receipt.AIR()
+
+	proof, err := stark.Prove(statement, receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	spec, err := solidity.ReceiptSpec(statement.TraceLength(), params)
+	if err != nil {
+		t.Fatalf("ReceiptSpec failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, spec.Name+".sol")
+
+	var out bytes.Buffer
+	if err = solidity.Generate(&out, spec); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if err = os.WriteFile(source, out.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if output, err := exec.Command("solc", "--optimize", "--via-ir", "--bin-runtime", "-o", dir, source).CombinedOutput(); err != nil {
+		t.Fatalf("solc failed: %v\n%s", err, output)
+	}
+
+	code := filepath.Join(dir, spec.Name+".bin-runtime")
+
+	words, err := solidity.EncodeProof(spec.Shape, proof)
+	if err != nil {
+		t.Fatalf("EncodeProof failed: %v", err)
+	}
+
+	// verify returns the ABI encoded bool, a revert counts as a rejection
+	verify := func(words []solidity.Word, total *math.PrimeField) bool {
+		input := hex.EncodeToString(solidity.Calldata(words, []*math.PrimeField{total}))
+
+		// The evm tool of go-ethereum 1.14 takes the code and the input as flags before the run command
+		output, err := exec.Command("evm", "--codefile", code, "--input", input, "--gas", "1000000000", "run").Output()
+		if err != nil {
+			return false
+		}
+
+		fields := strings.Fields(string(output))
+		return len(fields) > 0 && strings.TrimPrefix(fields[0], "0x") == strings.Repeat("0", 63)+"1"
+	}
+
+	if !verify(words, statement.Total) {
+		t.Fatalf("Contract rejected a valid proof")
+	}
+
+	if verify(words, new(math.PrimeField).Add(statement.Total, math.NewPrimeField(1))) {
+		t.Errorf("Contract accepted a wrong total")
+	}
+
+	layout := solidity.NewLayout(spec.Shape)
+	tampered := append([]solidity.Word{}, words...)
+	tampered[layout.OODCurrent][31] ^= 1
+	if verify(tampered, statement.Total) {
+		t.Errorf("Contract accepted a tampered out-of-domain frame")
+	}
+}
+
+func TestSoliditySpecRejects(t *testing.T) {
+	if _, err := solidity.ReceiptSpec(8, stark.DefaultParameters()); err == nil {
+		t.Errorf("ReceiptSpec accepted SHA-256 parameters")
+	}
+
+	if _, err := solidity.NewSpec(air.ComputeSortedItems(lineItems()).AIR(), keccakParameters()); err == nil {
+		t.Errorf("NewSpec accepted an AIR with auxiliary segments")
+	}
+
+	if _, err := solidity.NewSpec(&publicReceiptAIR{ReceiptAIR: &air.ReceiptAIR{Steps: 8, Total: math.NewPrimeField(1)}, public: []*math.PrimeField{math.NewPrimeField(1)}}, keccakParameters()); err == nil {
+		t.Errorf("NewSpec accepted an AIR with public inputs")
+	}
+
+	spec, err := solidity.NewSpec(&air.ReceiptAIR{Steps: 8, Total: math.NewPrimeField(1)}, keccakParameters())
+	if err != nil {
+		t.Fatalf("NewSpec failed: %v", err)
+	}
+
+	if err = solidity.Generate(&bytes.Buffer{}, spec); err == nil {
+		t.Errorf("Generate accepted a spec without a name and transition constraints")
+	}
+}
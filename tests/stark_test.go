@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func receiptPrices(n int) []*math.PrimeField {
+	prices := make([]*math.PrimeField, n)
+	for i := range prices {
+		prices[i] = math.NewPrimeField(int64(100 + 7*i))
+	}
+
+	return prices
+}
+
+func TestStarkProveVerify(t *testing.T) {
+	for _, hash := range []stark.HashFunction{stark.HashSHA256, stark.HashPoseidon2, stark.HashKeccak256} {
+		params := stark.DefaultParameters()
+		params.Hash = hash
+
+		receipt := air.ComputePadded(receiptPrices(20))
+		statement := receipt.AIR()
+
+		if statement.TraceLength() != 32 {
+			t.Fatalf("Expected padded trace of 32 rows, got %d", statement.TraceLength())
+		}
+
+		proof, err := stark.Prove(statement, receipt.Trace(), params)
+		if err != nil {
+			t.Fatalf("Prove failed with %v: %v", hash, err)
+		}
+
+		if len(proof.FRI.Roots) == 0 {
+			t.Errorf("Expected FRI layers for a trace of 32 rows")
+		}
+
+		if err = stark.Verify(statement, proof, params); err != nil {
+			t.Errorf("Verify failed with %v: %v", hash, err)
+		}
+
+		data, _ := proof.MarshalBinary()
+		var decoded stark.Proof
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+
+		if err = stark.Verify(statement, &decoded, params); err != nil {
+			t.Errorf("Verify failed after encoding round trip: %v", err)
+		}
+	}
+}
+
+func TestStarkRejectsWrongStatement(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputePadded(receiptPrices(5))
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	wrongTotal := receipt.AIR()
+	wrongTotal.Total = new(math.PrimeField).Add(wrongTotal.Total, math.NewPrimeField(1))
+
+	if err = stark.Verify(wrongTotal, proof, params); !errors.Is(err, stark.ErrVerification) {
+		t.Errorf("Verify accepted a wrong total: %v", err)
+	}
+
+	otherParams := params
+	otherParams.NumQueries++
+	if err = stark.Verify(receipt.AIR(), proof, otherParams); err == nil {
+		t.Errorf("Verify accepted a proof with different parameters")
+	}
+}
+
+// publicReceiptAIR is a receipt AIR whose statement includes a public input its constraints do not read
+type publicReceiptAIR struct {
+	*air.ReceiptAIR
+	public []*math.PrimeField
+}
+
+func (a *publicReceiptAIR) PublicInputs() []*math.PrimeField {
+	return a.public
+}
+
+func TestStarkBindsPublicInputs(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputePadded(receiptPrices(5))
+
+	statement := &publicReceiptAIR{ReceiptAIR: receipt.AIR(), public: []*math.PrimeField{math.NewPrimeField(42)}}
+	proof, err := stark.Prove(statement, receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(statement, proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	for _, public := range [][]*math.PrimeField{nil, {math.NewPrimeField(43)}, {math.NewPrimeField(42), math.NewPrimeField(0)}} {
+		other := &publicReceiptAIR{ReceiptAIR: receipt.AIR(), public: public}
+		if err = stark.Verify(other, proof, params); !errors.Is(err, stark.ErrVerification) {
+			t.Errorf("Verify accepted the public inputs %v: %v", public, err)
+		}
+	}
+}
+
+func TestStarkRejectsTamperedProof(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputePadded(receiptPrices(20))
+
+	prove := func() *stark.Proof {
+		proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+		if err != nil {
+			t.Fatalf("Prove failed: %v", err)
+		}
+		return proof
+	}
+
+	one := math.NewPrimeField(1)
+
+	tampers := map[string]func(p *stark.Proof){
+		"ood current": func(p *stark.Proof) { p.OOD.Current[0] = new(math.PrimeField).Add(p.OOD.Current[0], one) },
+		"ood next":    func(p *stark.Proof) { p.OOD.Next[1] = new(math.PrimeField).Add(p.OOD.Next[1], one) },
+		"ood composition": func(p *stark.Proof) {
+			p.OOD.Composition[0] = new(math.PrimeField).Add(p.OOD.Composition[0], one)
+		},
+		"trace root": func(p *stark.Proof) { p.TraceRoot[0] ^= 1 },
+		"trace value": func(p *stark.Proof) {
+			p.Queries[0].Trace.Values[0] = new(math.PrimeField).Add(p.Queries[0].Trace.Values[0], one)
+		},
+		"fri value": func(p *stark.Proof) {
+			p.Queries[0].FRI[0].Values[0] = new(math.PrimeField).Add(p.Queries[0].FRI[0].Values[0], one)
+		},
+		"remainder": func(p *stark.Proof) { p.FRI.Remainder[0] = new(math.PrimeField).Add(p.FRI.Remainder[0], one) },
+	}
+
+	for name, tamper := range tampers {
+		proof := prove()
+		tamper(proof)
+
+		if err := stark.Verify(receipt.AIR(), proof, params); err == nil {
+			t.Errorf("Verify accepted a proof with tampered %s", name)
+		}
+	}
+}
+
+func TestStarkRejectsInvalidTrace(t *testing.T) {
+	receipt := air.ComputePadded(receiptPrices(3))
+
+	trace := receipt.Trace()
+	trace[1].Coefficients[1] = math.NewPrimeField(1)
+
+	if _, err := stark.Prove(receipt.AIR(), trace, stark.DefaultParameters()); err == nil {
+		t.Errorf("Prove accepted a trace that does not satisfy the constraints")
+	}
+
+	odd := air.Compute(receiptPrices(4))
+	if _, err := stark.Prove(odd.AIR(), odd.Trace(), stark.DefaultParameters()); err == nil {
+		t.Errorf("Prove accepted a trace length that is not a power of two")
+	}
+}
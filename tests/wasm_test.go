@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/stark"
+	"github.com/KyrylR/simple-air/wasm"
+)
+
+func TestWasmVerify(t *testing.T) {
+	params := stark.DefaultParameters()
+	receipt := air.ComputePadded(receiptPrices(5))
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	total := receipt.AIR().Total
+	if err = wasm.Verify(data, fmt.Sprintf(`{"total": "%v"}`, total), params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	if err = wasm.Verify(data, fmt.Sprintf(`{"total": "%v", "steps": %d}`, total, receipt.AIR().TraceLength()), params); err != nil {
+		t.Errorf("Verify with steps failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		proof  []byte
+		inputs string
+	}{
+		{"wrong total", data, `{"total": "1"}`},
+		{"wrong steps", data, fmt.Sprintf(`{"total": "%v", "steps": 16}`, total)},
+		{"too many steps", data, fmt.Sprintf(`{"total": "%v", "steps": %d}`, total, wasm.MaxSteps*2)},
+		{"missing total", data, `{"steps": 8}`},
+		{"numeric total", data, `{"total": 1}`},
+		{"unknown field", data, `{"total": "1", "price": "1"}`},
+		{"malformed JSON", data, `{"total"`},
+		{"truncated proof", data[:len(data)/2], fmt.Sprintf(`{"total": "%v"}`, total)},
+	} {
+		if err = wasm.Verify(tc.proof, tc.inputs, params); err == nil {
+			t.Errorf("Verify accepted %s", tc.name)
+		}
+	}
+
+	if err = wasm.Verify(data, fmt.Sprintf(`{"total": "%v"}`, total), stark.Parameters{}); err == nil || !strings.Contains(err.Error(), "parameters") {
+		t.Errorf("Verify returned %v for other parameters", err)
+	}
+}
+
+// TestWasmDependencies checks that no package of the module in the WebAssembly build imports unsafe
+// or has cgo or assembly files, which also keeps the build within reach of TinyGo
+func TestWasmDependencies(t *testing.T) {
+	const module = "github.com/KyrylR/simple-air"
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not in PATH")
+	}
+
+	cmd := exec.Command("go", "list", "-deps", "-f",
+		`{{.ImportPath}}|{{join .Imports ","}}|{{len .CgoFiles}}|{{len .SFiles}}`, module+"/cmd/simple-air-wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go list failed: %v", err)
+	}
+
+	packages := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) != 4 || !strings.HasPrefix(fields[0], module) {
+			continue
+		}
+		packages++
+
+		for _, imported := range strings.Split(fields[1], ",") {
+			if imported == "unsafe" {
+				t.Errorf("%s imports unsafe", fields[0])
+			}
+		}
+
+		if fields[2] != "0" || fields[3] != "0" {
+			t.Errorf("%s has %s cgo and %s assembly files", fields[0], fields[2], fields[3])
+		}
+	}
+
+	if packages == 0 {
+		t.Errorf("go list returned no package of the module:\n%s", output)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+func zkParams() stark.Parameters {
+	params := stark.DefaultParameters()
+	params.NumQueries = 4
+	params.ZeroKnowledge = true
+
+	return params
+}
+
+// zkWitnesses returns two receipts with different prices and the same public statement
+func zkWitnesses() (*air.Receipt, *air.Receipt) {
+	first := make([]*math.PrimeField, 7)
+	second := make([]*math.PrimeField, 7)
+	for i := range 6 {
+		first[i] = math.NewPrimeField(int64(i + 1))
+		second[i] = math.NewPrimeField(int64(6 - i))
+	}
+	first[6] = math.NewPrimeField(0)
+	second[6] = math.NewPrimeField(0)
+
+	return air.Compute(first), air.Compute(second)
+}
+
+func TestZeroKnowledgeProveVerify(t *testing.T) {
+	params := zkParams()
+	receipt, _ := zkWitnesses()
+
+	proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), proof, params); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	data, _ := proof.MarshalBinary()
+	var decoded stark.Proof
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if err = stark.Verify(receipt.AIR(), &decoded, params); err != nil {
+		t.Errorf("Verify failed after encoding round trip: %v", err)
+	}
+
+	again, _ := stark.Prove(receipt.AIR(), receipt.Trace(), params)
+	if again.TraceRoot == proof.TraceRoot {
+		t.Errorf("Zero-knowledge proofs of the same trace have equal commitments")
+	}
+
+	proof.Queries[0].Trace.Salt[0] = new(math.PrimeField).Add(proof.Queries[0].Trace.Salt[0], math.NewPrimeField(1))
+	if err = stark.Verify(receipt.AIR(), proof, params); err == nil {
+		t.Errorf("Verify accepted a tampered salt")
+	}
+
+	plain := params
+	plain.ZeroKnowledge = false
+	if err = stark.Verify(receipt.AIR(), &decoded, plain); err == nil {
+		t.Errorf("Verify accepted a zero-knowledge proof with plain parameters")
+	}
+}
+
+// TestZeroKnowledgeIndependence checks that values revealed by the proof do not depend on the prices:
+// for two witnesses with the same total the revealed price column values must be uniformly distributed.
+// Without zero-knowledge the same values are deterministic and tell the witnesses apart.
+func TestZeroKnowledgeIndependence(t *testing.T) {
+	const (
+		samples = 200
+		buckets = 8
+		// chi-square critical value for 7 degrees of freedom at p = 0.0001
+		critical = 29.9
+	)
+
+	first, second := zkWitnesses()
+
+	plain := zkParams()
+	plain.ZeroKnowledge = false
+
+	plainFirst, _ := stark.Prove(first.AIR(), first.Trace(), plain)
+	plainSecond, _ := stark.Prove(second.AIR(), second.Trace(), plain)
+	if plainFirst.OOD.Current[0].Equals(plainSecond.OOD.Current[0]) {
+		t.Fatalf("Plain proofs of different witnesses reveal equal values")
+	}
+
+	revealed := func(p *stark.Proof) []*math.PrimeField {
+		return []*math.PrimeField{
+			p.OOD.Current[0], p.OOD.Next[0], p.OOD.Composition[0],
+			p.Queries[0].Trace.Values[0], p.Queries[0].Composition.Values[0], p.Queries[0].Composition.Values[1],
+		}
+	}
+
+	histograms := func(receipt *air.Receipt) [][]int {
+		var counts [][]int
+		for range samples {
+			proof, err := stark.Prove(receipt.AIR(), receipt.Trace(), zkParams())
+			if err != nil {
+				t.Fatalf("Prove failed: %v", err)
+			}
+
+			for len(counts) < len(revealed(proof)) {
+				counts = append(counts, make([]int, buckets))
+			}
+
+			for i, value := range revealed(proof) {
+				counts[i][value.Uint64()>>61]++
+			}
+		}
+
+		return counts
+	}
+
+	firstCounts := histograms(first)
+	secondCounts := histograms(second)
+
+	for i := range firstCounts {
+		uniform, twoSample := 0.0, 0.0
+
+		for b := range buckets {
+			expected := float64(samples) / buckets
+			for _, counts := range [][]int{firstCounts[i], secondCounts[i]} {
+				diff := float64(counts[b]) - expected
+				uniform += diff * diff / expected
+			}
+
+			if total := float64(firstCounts[i][b] + secondCounts[i][b]); total > 0 {
+				diff := float64(firstCounts[i][b] - secondCounts[i][b])
+				twoSample += diff * diff / total
+			}
+		}
+
+		// The uniform statistic sums two independent samples and has 14 degrees of freedom
+		if uniform > 2*critical {
+			t.Errorf("Revealed value %d is not uniformly distributed: chi-square %.1f", i, uniform)
+		}
+
+		if twoSample > critical {
+			t.Errorf("Revealed value %d depends on the witness: chi-square %.1f", i, twoSample)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+package wasm
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// Register sets the global JavaScript function verify(proofBytes, publicInputsJSON), which takes the proof
+// as a Uint8Array and returns {valid: true} or {valid: false, error: message}
+func Register(params stark.Parameters) {
+	js.Global().Set("verify", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		return result(verifyValues(args, params))
+	}))
+}
+
+func verifyValues(args []js.Value, params stark.Parameters) error {
+	if len(args) != 2 {
+		return fmt.Errorf("verify takes (proofBytes, publicInputsJSON), got %d arguments", len(args))
+	}
+
+	if !args[0].InstanceOf(js.Global().Get("Uint8Array")) {
+		return fmt.Errorf("proofBytes must be a Uint8Array")
+	}
+
+	if args[1].Type() != js.TypeString {
+		return fmt.Errorf("publicInputsJSON must be a string")
+	}
+
+	proofBytes := make([]byte, args[0].Length())
+	js.CopyBytesToGo(proofBytes, args[0])
+
+	return Verify(proofBytes, args[1].String(), params)
+}
+
+func result(err error) map[string]any {
+	if err != nil {
+		return map[string]any{"valid": false, "error": err.Error()}
+	}
+
+	return map[string]any{"valid": true}
+}
@@ -0,0 +1,77 @@
+// Package wasm verifies receipt proofs for the WebAssembly build of cmd/simple-air-wasm, which exposes
+// Verify to JavaScript as verify(proofBytes, publicInputsJSON).
+//
+// The verifier path is plain Go: package ff uses its generic field arithmetic, its generated vector reads
+// without unsafe and the hashes are implemented in Go, so the packages of the module compile under GOOS=js GOARCH=wasm
+// without cgo, unsafe or assembly, which tests/wasm_test.go checks with go list. Without them the build is also
+// a candidate for TinyGo, see the wasm-tinygo target of the Makefile, which is not run by the tests.
+// Only the bindings in js.go depend on syscall/js, Verify is tested on every platform.
+package wasm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/KyrylR/simple-air/air"
+	"github.com/KyrylR/simple-air/math"
+	"github.com/KyrylR/simple-air/stark"
+)
+
+// MaxSteps is the largest trace length verified, which bounds the memory a proof makes the browser allocate
+const MaxSteps = 1 << 16
+
+// PublicInputs are the public inputs of a receipt proof as passed from JavaScript
+type PublicInputs struct {
+	// Total is the claimed receipt total as a decimal field element
+	Total string `json:"total"`
+
+	// Steps is the trace length of the receipt, the one of the proof if 0
+	Steps int `json:"steps,omitempty"`
+}
+
+// ParsePublicInputs decodes the JSON public inputs, unknown fields are rejected
+func ParsePublicInputs(data string) (*PublicInputs, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	inputs := new(PublicInputs)
+	if err := decoder.Decode(inputs); err != nil {
+		return nil, fmt.Errorf("public inputs: %w", err)
+	}
+
+	if inputs.Total == "" {
+		return nil, fmt.Errorf("public inputs: missing total")
+	}
+
+	return inputs, nil
+}
+
+// Verify checks the binary proof of a receipt total given as JSON public inputs with the parameters
+func Verify(proofBytes []byte, publicInputs string, params stark.Parameters) error {
+	inputs, err := ParsePublicInputs(publicInputs)
+	if err != nil {
+		return err
+	}
+
+	total := new(math.PrimeField)
+	if err = total.UnmarshalText([]byte(inputs.Total)); err != nil {
+		return fmt.Errorf("total: %w", err)
+	}
+
+	proof := new(stark.Proof)
+	if err = proof.UnmarshalBinary(proofBytes); err != nil {
+		return fmt.Errorf("proof: %w", err)
+	}
+
+	statement := &air.ReceiptAIR{Steps: inputs.Steps, Total: total}
+	if statement.Steps == 0 {
+		statement.Steps = int(proof.TraceLength)
+	}
+
+	if statement.Steps > MaxSteps {
+		return fmt.Errorf("proof has %d steps, at most %d are verified", statement.Steps, MaxSteps)
+	}
+
+	return stark.Verify(statement, proof, params)
+}